@@ -0,0 +1,190 @@
+package main
+
+import (
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/agonzalezro/polo/site"
+	fsnotify "gopkg.in/fsnotify.v1"
+)
+
+// debounceDelay is the time without changes waited before rebuilding, the
+// editors usually emit several events when saving a file.
+const debounceDelay = 200 * time.Millisecond
+
+// daemon rebuilds the site when the content, the templates or the config
+// change and notifies the browsers after that.
+type daemon struct {
+	site          *site.Site
+	watcher       *fsnotify.Watcher
+	liveReload    *liveReload
+	templatesPath string
+
+	mux    *sync.Mutex
+	timer  *time.Timer
+	reload bool          // The next rebuild needs to reload the config and the templates
+	queue  chan struct{} // A rebuild already in progress can only have another one waiting
+	err    error         // The error of the last rebuild, if it failed
+}
+
+func newDaemon(s *site.Site, liveReload *liveReload) (*daemon, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &daemon{
+		site:          s,
+		watcher:       watcher,
+		liveReload:    liveReload,
+		templatesPath: filepath.Join(*templatesBasePath, site.TemplatesRelativePath),
+		mux:           &sync.Mutex{},
+		queue:         make(chan struct{}, 1),
+	}
+
+	d.watchTree(*source)
+	if dirExists(d.templatesPath) {
+		d.watchTree(d.templatesPath)
+	}
+	// Watch the directory, the editors usually replace the file when saving it
+	if err := watcher.Add(filepath.Dir(*configPath)); err != nil {
+		log.Error(err)
+	}
+
+	go d.watch()
+	go d.run()
+	return d, nil
+}
+
+func (d *daemon) Close() error {
+	return d.watcher.Close()
+}
+
+// watchTree watches the directory and all its subdirectories.
+func (d *daemon) watchTree(root string) {
+	for path := range subdirectories(root) {
+		if err := d.watcher.Add(path); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+// watch schedules a rebuild for the relevant events.
+func (d *daemon) watch() {
+	for {
+		select {
+		case event, ok := <-d.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				break
+			}
+
+			// The directory of the config file could have other stuff
+			isConfig := sameFile(event.Name, *configPath)
+			isTemplate := isInside(event.Name, d.templatesPath)
+			if !isConfig && !isTemplate && !isInside(event.Name, *source) {
+				break
+			}
+
+			if event.Op&fsnotify.Create != 0 && dirExists(event.Name) {
+				log.Debug("Watching the new directory: ", event.Name)
+				d.watchTree(event.Name)
+			}
+			d.schedule(isConfig || isTemplate)
+		case err, ok := <-d.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Error(err)
+		}
+	}
+}
+
+// schedule queues a rebuild after debounceDelay without new changes.
+func (d *daemon) schedule(reload bool) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	d.reload = d.reload || reload
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(debounceDelay, func() {
+		select {
+		case d.queue <- struct{}{}:
+		default:
+			// There is already a rebuild waiting, it will include these changes
+		}
+	})
+}
+
+// run does the queued rebuilds one by one.
+func (d *daemon) run() {
+	for range d.queue {
+		d.mux.Lock()
+		reload := d.reload
+		d.reload = false
+		d.mux.Unlock()
+
+		start := time.Now()
+		err := d.rebuild(reload)
+
+		d.mux.Lock()
+		d.err = err
+		if err != nil {
+			// Try to reload them again on the next change
+			d.reload = d.reload || reload
+		}
+		d.mux.Unlock()
+
+		if err != nil {
+			log.Error("The site couldn't be rebuilt: ", err)
+		} else {
+			log.Infof("Site rebuilt in %s", time.Since(start))
+		}
+		// The browsers show the error, or the site again after fixing it
+		d.liveReload.Reload()
+	}
+}
+
+func (d *daemon) lastError() error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	return d.err
+}
+
+// Handler serves an overlay with the error instead of the site until the next
+// successful rebuild. The last good output is kept meanwhile.
+func (d *daemon) Handler(site http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := d.lastError(); err != nil {
+			serveOverlay(w, err)
+			return
+		}
+		site.ServeHTTP(w, r)
+	})
+}
+
+// rebuild loads the content again and writes the site. If reload is true the
+// config and the templates are loaded again too. The current site is kept if
+// the new one can't be created.
+func (d *daemon) rebuild(reload bool) error {
+	if !reload {
+		log.Info("Rebuilding the site")
+		return d.site.Rebuild()
+	}
+
+	log.Info("Reloading the config and the templates")
+	site.ResetTemplates()
+	s, err := newSite()
+	if err != nil {
+		return err
+	}
+	d.site = s
+	return s.Write()
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// liveReloadPath is where the browsers listen for the reload events.
+const liveReloadPath = "/_polo/livereload"
+
+var liveReloadScript = fmt.Sprintf(`<script>
+  new EventSource("%s").onmessage = function() { location.reload(); };
+</script>
+`, liveReloadPath)
+
+// liveReload notifies the connected browsers with Server-Sent Events when the
+// site is rewritten.
+type liveReload struct {
+	mux     *sync.Mutex
+	clients map[chan string]bool
+}
+
+func newLiveReload() *liveReload {
+	return &liveReload{
+		mux:     &sync.Mutex{},
+		clients: make(map[chan string]bool),
+	}
+}
+
+// Reload tells to all the browsers that they need to reload the page.
+func (lr *liveReload) Reload() {
+	lr.mux.Lock()
+	defer lr.mux.Unlock()
+
+	for client := range lr.clients {
+		select {
+		case client <- "reload":
+		default:
+			// The client already has a reload pending
+		}
+	}
+}
+
+func (lr *liveReload) subscribe() chan string {
+	lr.mux.Lock()
+	defer lr.mux.Unlock()
+
+	client := make(chan string, 1)
+	lr.clients[client] = true
+	return client
+}
+
+func (lr *liveReload) unsubscribe(client chan string) {
+	lr.mux.Lock()
+	defer lr.mux.Unlock()
+	delete(lr.clients, client)
+}
+
+// ServeHTTP keeps the connection open sending the events to the browser.
+func (lr *liveReload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	client := lr.subscribe()
+	defer lr.unsubscribe(client)
+	log.Debug("Live reload client connected: ", r.RemoteAddr)
+
+	for {
+		select {
+		case event := <-client:
+			fmt.Fprintf(w, "data: %s\n\n", event)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// Handler serves the site injecting the live reload script on the HTML files.
+func (lr *liveReload) Handler(site http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(liveReloadPath, lr)
+	mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			site.ServeHTTP(w, r)
+			return
+		}
+		iw := &injectingWriter{ResponseWriter: w}
+		site.ServeHTTP(iw, r)
+		iw.Close()
+	}))
+	return mux
+}
+
+// injectingWriter buffers the HTML responses to add the live reload script
+// before the end of the body.
+type injectingWriter struct {
+	http.ResponseWriter
+
+	isHTML      bool
+	wroteHeader bool
+	status      int
+	buf         bytes.Buffer
+}
+
+func (w *injectingWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		w.isHTML = true
+		// The length will change after injecting the script
+		w.Header().Del("Content-Length")
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *injectingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.isHTML {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Close writes the buffered HTML with the script.
+func (w *injectingWriter) Close() {
+	if !w.isHTML {
+		return
+	}
+
+	html := w.buf.String()
+	if i := strings.LastIndex(strings.ToLower(html), "</body>"); i != -1 {
+		html = html[:i] + liveReloadScript + html[i:]
+	} else {
+		html += liveReloadScript
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if _, err := w.ResponseWriter.Write([]byte(html)); err != nil {
+		log.Debug(err)
+	}
+}
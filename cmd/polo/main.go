@@ -3,7 +3,9 @@ package main
 //go:generate go-bindata -o ../../templates/assets.go -pkg=assets -ignore=.DS_Store -ignore=assets.go -prefix=../.. ../../templates/...
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 
@@ -11,15 +13,21 @@ import (
 	config "github.com/agonzalezro/polo/config"
 	"github.com/agonzalezro/polo/site"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
-	fsnotify "gopkg.in/fsnotify.v1"
 )
 
 var (
 	app = kingpin.New("polo", `Static site generator "compatible" with Jekyll & Pelican content.`)
 
-	startDaemon = app.Flag("start-daemon", "Start a simple HTTP server watching for markdown changes.").Short('d').Bool()
-	port        = app.Flag("port", "Port where to run the server.").Default("8080").Short('p').Int()
-	configPath  = app.Flag("config", "The settings file.").Short('c').Default("config.json").String()
+	startDaemon  = app.Flag("start-daemon", "Start a simple HTTP server watching for markdown changes.").Short('d').Bool()
+	port         = app.Flag("port", "Port where to run the server.").Default("8080").Short('p').Int()
+	configPath   = app.Flag("config", "The settings file.").Short('c').Default("config.json").String()
+	buildFuture  = app.Flag("build-future", "Include the articles dated in the future.").Bool()
+	drafts       = app.Flag("drafts", "Include the drafts, to preview them.").Bool()
+	force        = app.Flag("force", "Write all the files, even the ones that didn't change since the last build.").Bool()
+	clean        = app.Flag("clean", "Write the whole site again, without the files of the last build.").Bool()
+	jobs         = app.Flag("jobs", "Number of files written at the same time, one per CPU by default.").Short('j').Int()
+	stats        = app.Flag("stats", "Show the numbers and the timings of the build.").Bool()
+	errorsReport = app.Flag("errors-report", "Write the build errors as JSON on this file, ex: for the CI.").String()
 
 	templatesBasePath = app.Flag("templates-base-path", fmt.Sprintf("Where the '%s/' folder resides (in case it exists).", site.TemplatesRelativePath)).Default(".").ExistingDir()
 
@@ -29,6 +37,11 @@ var (
 	output = app.Arg("output", "Where to store the published files.").Required().String()
 )
 
+func newSite() (*site.Site, error) {
+	options := site.Options{BuildFuture: *buildFuture, Drafts: *drafts, Force: *force, Jobs: *jobs}
+	return site.New(*source, *output, *configPath, *templatesBasePath, options)
+}
+
 func main() {
 	app.HelpFlag.Short('h')
 	kingpin.MustParse(app.Parse(os.Args[1:]))
@@ -43,49 +56,81 @@ func main() {
 		}
 	}
 
-	s, err := site.New(*source, *output, *configPath, *templatesBasePath)
+	s, err := newSite()
 	if err != nil {
 		switch err.(type) {
+		case site.BuildErrors:
+			exitWithErrors(err)
 		case config.ErrorParsingConfigFile:
 			app.FatalUsage("Malformed JSON config file: ", err)
 		default:
-			log.Fatal(err)
+			exitWithErrors(err)
 		}
 	}
 
-	if err := s.Write(); err != nil {
+	write := s.Write
+	if *clean {
+		write = s.WriteClean
+	}
+	if err := write(); err != nil {
+		exitWithErrors(err)
+	}
+	if err := writeErrorsReport(nil); err != nil {
 		log.Fatal(err)
 	}
+	if *stats {
+		if err := printStats(os.Stdout, s.Stats()); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	if *startDaemon {
-		watcher, err := fsnotify.NewWatcher()
+		liveReload := newLiveReload()
+		d, err := newDaemon(s, liveReload)
 		if err != nil {
 			log.Fatal(err)
 		}
-		defer watcher.Close()
-
-		go func() {
-			for {
-				select {
-				case event := <-watcher.Events:
-					if event.Op != fsnotify.Chmod {
-						log.Info("Rewriting the site")
-						if err := s.Write(); err != nil {
-							log.Fatal(err)
-						}
-					}
-				case err := <-watcher.Errors:
-					log.Fatal(err) // TODO: perhaps return err
-				}
-			}
-		}()
-
-		for path := range subdirectories(*source) {
-			watcher.Add(path)
-		}
+		defer d.Close()
 
 		addr := fmt.Sprintf(":%d", *port)
 		log.Info("Static server running on ", addr)
-		log.Fatal(http.ListenAndServe(addr, http.FileServer(http.Dir(*output))))
+		log.Fatal(http.ListenAndServe(addr, liveReload.Handler(d.Handler(http.FileServer(http.Dir(*output))))))
+	}
+}
+
+// exitWithErrors prints a summary of the build errors and exits with a non
+// zero code.
+func exitWithErrors(err error) {
+	buildErrs, ok := err.(site.BuildErrors)
+	if !ok {
+		buildErrs = site.BuildErrors{{Err: err}}
+	}
+
+	for _, buildErr := range buildErrs {
+		log.Error(buildErr)
+	}
+	if err := writeErrorsReport(buildErrs); err != nil {
+		log.Error("The errors report couldn't be written: ", err)
+	}
+	log.Errorf("The site couldn't be built: %d error(s)", len(buildErrs))
+	os.Exit(1)
+}
+
+// writeErrorsReport writes the errors as JSON if --errors-report is set. The
+// report is written for the successful builds too, with an empty list.
+func writeErrorsReport(errs site.BuildErrors) error {
+	if *errorsReport == "" {
+		return nil
+	}
+	if errs == nil {
+		errs = site.BuildErrors{}
+	}
+
+	b, err := json.MarshalIndent(struct {
+		Errors site.BuildErrors `json:"errors"`
+	}{errs}, "", "  ")
+	if err != nil {
+		return err
 	}
+	return ioutil.WriteFile(*errorsReport, b, 0644)
 }
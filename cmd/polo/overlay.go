@@ -0,0 +1,95 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/agonzalezro/polo/file"
+	"github.com/agonzalezro/polo/site"
+)
+
+var overlayTemplate = template.Must(template.New("overlay").Parse(`<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>Build failed | polo</title>
+    <style>
+      body { margin: 0; background: #222; color: #eee; font-family: monospace; }
+      .overlay { padding: 2em; }
+      h1 { color: #ff5555; font-size: 1.5em; }
+      .location { color: #f1fa8c; }
+      pre { white-space: pre-wrap; font-size: 1.1em; }
+    </style>
+  </head>
+  <body>
+    <div class="overlay">
+      <h1>The site couldn't be built</h1>
+      {{range .}}
+      {{if .Path}}
+      <p class="location">{{.Path}}{{if .Line}}:{{.Line}}{{end}}</p>
+      {{end}}
+      <pre>{{.Message}}</pre>
+      {{end}}
+      <p>The page will be reloaded after fixing the error.</p>
+    </div>
+  </body>
+</html>
+`))
+
+// templateErrorRe matches the location of the errors returned by the
+// templates, ex: "template: index.tmpl:85: missing value for if".
+var templateErrorRe = regexp.MustCompile(`^template: ([^:]+):(\d+):(?:\d+:)?\s*(.*)$`)
+
+// buildError is the location of an error, if it's known, and its message.
+type buildError struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+// newBuildErrors returns one buildError for every error of the build.
+func newBuildErrors(err error) []buildError {
+	buildErrs, ok := err.(site.BuildErrors)
+	if !ok {
+		return []buildError{newBuildError(err)}
+	}
+
+	var errs []buildError
+	for _, buildErr := range buildErrs {
+		e := newBuildError(buildErr.Err)
+		if e.Path == "" {
+			// The location of the template is better, if it's known
+			e.Path = buildErr.Output
+			if buildErr.Source != "" {
+				e.Path = buildErr.Source
+			}
+		}
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+func newBuildError(err error) buildError {
+	if parseErr, ok := err.(*file.ParseError); ok {
+		return buildError{Path: parseErr.Path, Line: parseErr.Line, Message: parseErr.Err.Error()}
+	}
+
+	if matches := templateErrorRe.FindStringSubmatch(err.Error()); matches != nil {
+		line, _ := strconv.Atoi(matches[2])
+		return buildError{Path: matches[1], Line: line, Message: matches[3]}
+	}
+	return buildError{Message: err.Error()}
+}
+
+// serveOverlay shows the errors instead of the page requested.
+func serveOverlay(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusInternalServerError)
+	if err := overlayTemplate.Execute(w, newBuildErrors(err)); err != nil {
+		log.Debug(err)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/agonzalezro/polo/site"
+)
+
+// slowestOutputs is how many of the slowest outputs and templates are shown.
+const slowestOutputs = 10
+
+// printStats writes a report with the numbers of the last build.
+func printStats(w io.Writer, st site.Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Content:")
+	fmt.Fprintf(tw, "  articles\t%d\n", st.Articles)
+	fmt.Fprintf(tw, "  pages\t%d\n", st.Pages)
+	fmt.Fprintf(tw, "  drafts\t%d\n", st.Drafts)
+	fmt.Fprintf(tw, "  tags\t%d\n", st.Tags)
+	fmt.Fprintf(tw, "  categories\t%d\n", st.Categories)
+
+	fmt.Fprintln(tw, "Output:")
+	fmt.Fprintf(tw, "  files written\t%d\n", st.Written)
+	fmt.Fprintf(tw, "  files up to date\t%d\n", st.UpToDate)
+	fmt.Fprintf(tw, "  bytes written\t%d\n", st.Bytes)
+
+	fmt.Fprintln(tw, "Time:")
+	fmt.Fprintf(tw, "  loading\t%s\n", round(st.Load))
+	fmt.Fprintf(tw, "  markdown rendering\t%s\n", round(st.Markdown))
+	fmt.Fprintf(tw, "  template execution\t%s\n", round(st.Templates))
+	fmt.Fprintf(tw, "  disk writes\t%s\n", round(st.Disk))
+	fmt.Fprintf(tw, "  writing the site\t%s\n", round(st.Write))
+
+	if templates := st.SlowestTemplates(slowestOutputs); len(templates) > 0 {
+		fmt.Fprintln(tw, "Slowest templates:")
+		for _, template := range templates {
+			fmt.Fprintf(tw, "  %s\t%s\t%d files\n", template.Template, round(template.Duration), template.Outputs)
+		}
+	}
+
+	if outputs := st.Slowest(slowestOutputs); len(outputs) > 0 {
+		fmt.Fprintln(tw, "Slowest files:")
+		for _, output := range outputs {
+			fmt.Fprintf(tw, "  %s\t%s\t%d bytes", output.Output, round(output.Duration), output.Bytes)
+			if output.Source != "" {
+				fmt.Fprintf(tw, "\t%s", output.Source)
+			}
+			fmt.Fprintln(tw)
+		}
+	}
+	return tw.Flush()
+}
+
+// round makes the durations readable, nobody cares about the nanoseconds.
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
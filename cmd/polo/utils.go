@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
 )
@@ -12,7 +13,10 @@ func subdirectories(parentPath string) chan string {
 
 	walkFn := func(path string, info os.FileInfo, err error) error {
 		if err != nil {
+			// The new directories can be removed before walking them, ex:
+			// the temporary ones of the editors
 			log.Warning(err)
+			return nil
 		}
 		if info.IsDir() {
 			paths <- path
@@ -34,3 +38,17 @@ func dirExists(p string) bool {
 	}
 	return false
 }
+
+// isInside returns true if the path is the directory or it's inside of it.
+func isInside(p, dir string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// sameFile returns true if both paths point to the same file, even if it
+// doesn't exist anymore.
+func sameFile(p1, p2 string) bool {
+	abs1, err1 := filepath.Abs(p1)
+	abs2, err2 := filepath.Abs(p2)
+	return err1 == nil && err2 == nil && abs1 == abs2
+}
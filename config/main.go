@@ -2,7 +2,10 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 )
 
 // Config stores the configurations readed from the JSON file.
@@ -19,9 +22,93 @@ type Config struct {
 
 	PaginationSize int
 
+	// SummaryLength is the number of words of the summaries generated from
+	// the content. If it's not set the first paragraph is used.
+	SummaryLength int
+
+	// DateFromModTime uses the modification time of the files without date.
+	DateFromModTime bool
+
+	// ModifiedFromGit uses the last commit touching the files without
+	// modified date. The history is read from the local .git directory.
+	ModifiedFromGit bool
+
+	// Timezone is used for the dates without one, ex: "Europe/Madrid". UTC
+	// by default.
+	Timezone string
+	location *time.Location
+
+	// WordsPerMinute is used to calculate the reading time of the articles.
+	WordsPerMinute int
+
+	// HeadingAnchors adds a link to itself to every heading of the content.
+	HeadingAnchors bool
+
+	// Prune removes the files of the output that were not written by the
+	// build, except the ones matching PruneIgnore, ex: "CNAME".
+	Prune       bool
+	PruneIgnore []string
+
 	DisqusSitename     string
 	GoogleAnalyticsID  string
 	ShareThisPublisher string
+
+	Markdown  Markdown
+	Highlight Highlight
+}
+
+// Markdown engines that can be used to render the content.
+const (
+	BlackfridayEngine = "blackfriday"
+	CommonMarkEngine  = "commonmark"
+)
+
+// Markdown stores the configuration used to render the markdown files.
+type Markdown struct {
+	// Engine is blackfriday by default, commonmark can be used to get
+	// CommonMark compliant output.
+	Engine string
+
+	Footnotes       bool
+	DefinitionLists bool
+	HeadingIDs      bool
+	HardLineBreaks  bool
+	NoSmartypants   bool
+}
+
+// Default values for the optional settings.
+const (
+	DefaultWordsPerMinute = 200
+
+	DefaultHighlightStyle      = "github"
+	DefaultHighlightStylesheet = "css/highlight.css"
+)
+
+// DefaultPruneIgnore are the files of the output never removed by default.
+var DefaultPruneIgnore = []string{".git", "CNAME"}
+
+// Highlight stores the configuration used to highlight the code blocks when
+// the site is built.
+type Highlight struct {
+	Enabled bool
+
+	// Style is the name of the chroma style, ex: "monokai".
+	Style string
+	// CSSClasses will use CSS classes instead of inline styles. The CSS for
+	// them will be written on the Stylesheet path of the output.
+	CSSClasses bool
+	Stylesheet string
+	// LineNumbers can be overridden on each code block.
+	LineNumbers bool
+}
+
+// Location returns the location of the configured time zone, UTC if it's not
+// configured.
+func (c Config) Location() *time.Location {
+	if c.location == nil {
+		return time.UTC
+	}
+	return c.location
 }
 
 // ErrorOpeningConfigFile will be raised when the file doesn't exist.
@@ -36,6 +123,7 @@ func New(configFile string) (*Config, error) {
 	if err != nil {
 		return nil, ErrorOpeningConfigFile(err)
 	}
+	defer file.Close()
 
 	decoder := json.NewDecoder(file)
 	config := &Config{}
@@ -44,5 +132,31 @@ func New(configFile string) (*Config, error) {
 	if err != nil {
 		return nil, ErrorParsingConfigFile(err)
 	}
+
+	switch strings.ToLower(config.Markdown.Engine) {
+	case "", BlackfridayEngine, CommonMarkEngine:
+	default:
+		return nil, ErrorParsingConfigFile(fmt.Errorf("Unknown markdown engine '%s'", config.Markdown.Engine))
+	}
+
+	if config.Timezone != "" {
+		config.location, err = time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, ErrorParsingConfigFile(err)
+		}
+	}
+
+	if config.WordsPerMinute <= 0 {
+		config.WordsPerMinute = DefaultWordsPerMinute
+	}
+	if config.PruneIgnore == nil {
+		config.PruneIgnore = DefaultPruneIgnore
+	}
+	if config.Highlight.Style == "" {
+		config.Highlight.Style = DefaultHighlightStyle
+	}
+	if config.Highlight.Stylesheet == "" {
+		config.Highlight.Stylesheet = DefaultHighlightStylesheet
+	}
 	return config, nil
 }
@@ -26,7 +26,10 @@ type Context struct {
 	Category    string
 	CurrentPage int
 
-	tagUniquenessMux, categoryUniquenessMux, numberOfPagesMux *sync.Mutex
+	// Of all the articles, the copies with less articles keep it
+	numberOfPages int
+
+	tagUniquenessMux, categoryUniquenessMux *sync.Mutex
 }
 
 func New(config config.Config) *Context {
@@ -35,36 +38,34 @@ func New(config config.Config) *Context {
 		Updated:               time.Now().Format(time.RFC3339),
 		tagUniquenessMux:      &sync.Mutex{},
 		categoryUniquenessMux: &sync.Mutex{},
-		numberOfPagesMux:      &sync.Mutex{},
 	}
 }
 
 func (c *Context) Copy() *Context {
 	return &Context{
 		Config:     c.Config,
+		Updated:    c.Updated,
 		Pages:      c.Pages,
 		Articles:   c.Articles,
 		Tags:       c.Tags,
 		Categories: c.Categories,
-	}
-}
 
-// TODO: fix this; we need a number of pages that doesn't fluctuate with the number of articles in the current context
-var numberOfPages int
-
-func (c Context) NumberOfPages() int {
-	if numberOfPages != 0 {
-		return numberOfPages
+		numberOfPages: c.numberOfPages,
 	}
+}
 
-	// TODO: we can't use a pointer to context so I doubt that this mutex does anything at all
-	c.numberOfPagesMux.Lock()
-	defer c.numberOfPagesMux.Unlock()
-
-	numberOfPages = int(
+// Paginate calculates the number of pages of the index with the articles of
+// the context. It must be called after loading all of them.
+func (c *Context) Paginate() {
+	c.numberOfPages = int(
 		math.Ceil(
 			float64(len(c.Articles)) / float64(c.Config.PaginationSize)))
-	return numberOfPages
+}
+
+// NumberOfPages returns the number of pages calculated by Paginate, the copies
+// of the context with less articles don't change it.
+func (c Context) NumberOfPages() int {
+	return c.numberOfPages
 }
 
 // PreviousSlug "calculates" the previous index slug given the page number.
@@ -1,7 +1,6 @@
 package context
 
 import (
-	"sync"
 	"testing"
 
 	"github.com/agonzalezro/polo/config"
@@ -12,25 +11,17 @@ import (
 func TestNumberOfPages(t *testing.T) {
 	assert := assert.New(t)
 
-	config := config.Config{
-		PaginationSize: 1,
-	}
-	c := Context{
-		Config:           config,
-		numberOfPagesMux: &sync.Mutex{},
-		Articles:         []file.ParsedFile{file.ParsedFile{}, file.ParsedFile{}},
-	}
+	c := New(config.Config{PaginationSize: 1})
+	c.Articles = []file.ParsedFile{file.ParsedFile{}, file.ParsedFile{}}
+	assert.Equal(0, c.NumberOfPages())
+	c.Paginate()
 	assert.Equal(2, c.NumberOfPages())
 
-	// Same context, old value
-	c.Articles = []file.ParsedFile{file.ParsedFile{}}
-	assert.Equal(2, c.NumberOfPages())
+	// The copies with less articles, ex: the indexes, keep it
+	copied := c.Copy()
+	copied.Articles = copied.Articles[:1]
+	assert.Equal(2, copied.NumberOfPages())
 
-	// New context, old value
-	c = Context{
-		Config:           config,
-		numberOfPagesMux: &sync.Mutex{},
-		Articles:         []file.ParsedFile{},
-	}
-	assert.Equal(2, c.NumberOfPages())
+	// New contexts don't share it
+	assert.Equal(0, New(config.Config{PaginationSize: 1}).NumberOfPages())
 }
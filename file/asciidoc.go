@@ -0,0 +1,196 @@
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/agonzalezro/polo/config"
+)
+
+// AsciiDocReader renders a lite version of AsciiDoc: sections, paragraphs,
+// lists, listing/literal/quote blocks, comments and the common inline markup.
+type AsciiDocReader struct{}
+
+// NewAsciiDocReader returns an AsciiDocReader, it doesn't need any
+// configuration.
+func NewAsciiDocReader(config.Config) Reader {
+	return AsciiDocReader{}
+}
+
+var asciiDocTitleRe = regexp.MustCompile(`^=+\s+`)
+
+func (AsciiDocReader) Title(line string) (string, bool) {
+	return strings.TrimSpace(asciiDocTitleRe.ReplaceAllString(line, "")), true
+}
+
+func (AsciiDocReader) HTML(content string) template.HTML {
+	r := asciiDocRenderer{lines: strings.Split(content, "\n")}
+	return template.HTML(r.render())
+}
+
+var (
+	asciiDocSectionRe    = regexp.MustCompile(`^(={2,6})\s+(.*)$`)
+	asciiDocUnorderedRe  = regexp.MustCompile(`^\s*[*-]+\s+`)
+	asciiDocOrderedRe    = regexp.MustCompile(`^\s*(\.+|\d+\.)\s+`)
+	asciiDocAttributesRe = regexp.MustCompile(`^\[(.*)\]$`)
+	asciiDocInlineRe     = regexp.MustCompile("`([^`]+)`|\\*([^*]+)\\*|\\b_([^_]+)_\\b|(?:link:)?((?:https?|ftp|mailto)://[^\\s\\[]+)\\[([^\\]]*)\\]")
+)
+
+// Delimiters of the blocks and the tag used to render them.
+var asciiDocBlocks = map[string]string{
+	"----": "listing",
+	"....": "literal",
+	"____": "quote",
+	"////": "comment",
+}
+
+type asciiDocRenderer struct {
+	lines []string
+	i     int
+	buf   bytes.Buffer
+}
+
+// block returns the lines until the closing delimiter.
+func (r *asciiDocRenderer) block(delimiter string) []string {
+	var lines []string
+	for r.i++; r.i < len(r.lines); r.i++ {
+		if strings.TrimRight(r.lines[r.i], " ") == delimiter {
+			r.i++
+			break
+		}
+		lines = append(lines, r.lines[r.i])
+	}
+	return lines
+}
+
+func (r *asciiDocRenderer) writeList(tag string, itemRe *regexp.Regexp) {
+	fmt.Fprintf(&r.buf, "<%s>\n", tag)
+	for r.i < len(r.lines) {
+		line := r.lines[r.i]
+		if isBlank(line) {
+			if r.i+1 < len(r.lines) && itemRe.MatchString(r.lines[r.i+1]) {
+				r.i++
+				continue
+			}
+			break
+		}
+		if !itemRe.MatchString(line) {
+			break
+		}
+
+		item := []string{itemRe.ReplaceAllString(line, "")}
+		r.i++
+		for ; r.i < len(r.lines) && !isBlank(r.lines[r.i]) && !itemRe.MatchString(r.lines[r.i]); r.i++ {
+			item = append(item, strings.TrimSpace(r.lines[r.i]))
+		}
+		fmt.Fprintf(&r.buf, "<li>%s</li>\n", asciiDocInline(strings.Join(item, " ")))
+	}
+	fmt.Fprintf(&r.buf, "</%s>\n", tag)
+}
+
+func (r *asciiDocRenderer) render() string {
+	var attributes []string
+	for r.i < len(r.lines) {
+		line := strings.TrimRight(r.lines[r.i], " ")
+
+		if kind, ok := asciiDocBlocks[line]; ok {
+			lines := r.block(line)
+			switch kind {
+			case "listing":
+				// [source,go] sets the language of the listing
+				if len(attributes) > 1 && attributes[0] == "source" {
+					fmt.Fprintf(&r.buf, "<pre><code class=\"language-%s\">", html.EscapeString(attributes[1]))
+				} else {
+					r.buf.WriteString("<pre><code>")
+				}
+				fmt.Fprintf(&r.buf, "%s\n</code></pre>\n", html.EscapeString(strings.Join(lines, "\n")))
+			case "literal":
+				fmt.Fprintf(&r.buf, "<pre>%s\n</pre>\n", html.EscapeString(strings.Join(lines, "\n")))
+			case "quote":
+				quote := asciiDocRenderer{lines: lines}
+				fmt.Fprintf(&r.buf, "<blockquote>\n%s</blockquote>\n", quote.render())
+			}
+			attributes = nil
+			continue
+		}
+
+		switch {
+		case isBlank(line):
+			r.i++
+		case strings.HasPrefix(line, "//"):
+			r.i++
+		case asciiDocAttributesRe.MatchString(line):
+			attributes = strings.Split(asciiDocAttributesRe.FindStringSubmatch(line)[1], ",")
+			for i := range attributes {
+				attributes[i] = strings.TrimSpace(attributes[i])
+			}
+			r.i++
+			continue
+		case line == "'''":
+			r.buf.WriteString("<hr>\n")
+			r.i++
+		case asciiDocSectionRe.MatchString(line):
+			matches := asciiDocSectionRe.FindStringSubmatch(line)
+			level := len(matches[1])
+			fmt.Fprintf(&r.buf, "<h%d>%s</h%d>\n", level, asciiDocInline(matches[2]), level)
+			r.i++
+		case asciiDocUnorderedRe.MatchString(line):
+			r.writeList("ul", asciiDocUnorderedRe)
+		case asciiDocOrderedRe.MatchString(line):
+			r.writeList("ol", asciiDocOrderedRe)
+		case indentation(line) > 0:
+			// Indented paragraphs are literal
+			var lines []string
+			for ; r.i < len(r.lines) && !isBlank(r.lines[r.i]); r.i++ {
+				lines = append(lines, strings.TrimSpace(r.lines[r.i]))
+			}
+			fmt.Fprintf(&r.buf, "<pre>%s\n</pre>\n", html.EscapeString(strings.Join(lines, "\n")))
+		default:
+			var lines []string
+			for ; r.i < len(r.lines) && !isBlank(r.lines[r.i]); r.i++ {
+				if _, ok := asciiDocBlocks[strings.TrimRight(r.lines[r.i], " ")]; ok {
+					break
+				}
+				lines = append(lines, strings.TrimSpace(r.lines[r.i]))
+			}
+			fmt.Fprintf(&r.buf, "<p>%s</p>\n", asciiDocInline(strings.Join(lines, " ")))
+		}
+		attributes = nil
+	}
+	return r.buf.String()
+}
+
+// asciiDocInline renders the inline markup: `monospace`, *strong*, _emphasis_
+// and http://example.com[links].
+func asciiDocInline(s string) string {
+	var buf bytes.Buffer
+	last := 0
+	for _, m := range asciiDocInlineRe.FindAllStringSubmatchIndex(s, -1) {
+		buf.WriteString(html.EscapeString(s[last:m[0]]))
+		last = m[1]
+
+		group := func(n int) string {
+			return html.EscapeString(s[m[2*n]:m[2*n+1]])
+		}
+		switch {
+		case m[2] != -1:
+			fmt.Fprintf(&buf, "<code>%s</code>", group(1))
+		case m[4] != -1:
+			fmt.Fprintf(&buf, "<strong>%s</strong>", group(2))
+		case m[6] != -1:
+			fmt.Fprintf(&buf, "<em>%s</em>", group(3))
+		case m[8] != -1:
+			text := group(5)
+			if text == "" {
+				text = group(4)
+			}
+			fmt.Fprintf(&buf, "<a href=\"%s\">%s</a>", group(4), text)
+		}
+	}
+	buf.WriteString(html.EscapeString(s[last:]))
+	return buf.String()
+}
@@ -0,0 +1,141 @@
+package file
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// gitHistory stores the time of the last commit touching each file of a
+// repository.
+type gitHistory struct {
+	head     plumbing.Hash
+	modified map[string]time.Time // By path relative to the repository
+}
+
+var (
+	gitHistoriesMux = &sync.Mutex{}
+	gitHistories    = map[string]*gitHistory{} // By repository root
+)
+
+// ModifiedFromGit returns the time of the last commit touching the file. It's
+// read from the .git directory of the repository containing the path, and it's
+// zero if the path isn't in a repository or it was never committed.
+func ModifiedFromGit(path string) (time.Time, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	repo, err := git.PlainOpenWithOptions(filepath.Dir(path), &git.PlainOpenOptions{DetectDotGit: true})
+	if err == git.ErrRepositoryNotExists {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return time.Time{}, err
+	}
+	root := worktree.Filesystem.Root()
+	relPath, err := filepath.Rel(root, path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	history, err := historyOf(root, repo)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return history.modified[filepath.ToSlash(relPath)], nil
+}
+
+// historyOf returns the history of the repository, it's walked only once
+// until the HEAD changes.
+func historyOf(root string, repo *git.Repository) (*gitHistory, error) {
+	head, err := repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		// There are no commits yet
+		return &gitHistory{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gitHistoriesMux.Lock()
+	defer gitHistoriesMux.Unlock()
+
+	if history, ok := gitHistories[root]; ok && history.head == head.Hash() {
+		return history, nil
+	}
+
+	history := &gitHistory{head: head.Hash(), modified: make(map[string]time.Time)}
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+
+	// The commits are sorted from the newest, so the first one touching a file
+	// is the last time it was modified.
+	err = commits.ForEach(func(commit *object.Commit) error {
+		paths, err := changedPaths(commit)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			if _, ok := history.modified[path]; !ok {
+				history.modified[path] = commit.Committer.When
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gitHistories[root] = history
+	return history, nil
+}
+
+// changedPaths returns the paths added or modified by the commit compared with
+// its first parent, or all the files for the root commit.
+func changedPaths(commit *object.Commit) ([]string, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	if commit.NumParents() == 0 {
+		err := tree.Files().ForEach(func(f *object.File) error {
+			paths = append(paths, f.Name)
+			return nil
+		})
+		return paths, err
+	}
+
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return nil, err
+	}
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		if change.To.Name != "" {
+			paths = append(paths, change.To.Name)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,59 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestModifiedFromGit(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "polo")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(err)
+	worktree, err := repo.Worktree()
+	assert.NoError(err)
+
+	commit := func(when time.Time, files ...string) {
+		for _, name := range files {
+			path := filepath.Join(dir, name)
+			assert.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+			assert.NoError(ioutil.WriteFile(path, []byte(when.String()), 0644))
+			_, err := worktree.Add(name)
+			assert.NoError(err)
+		}
+		signature := &object.Signature{Name: "polo", Email: "polo@example.com", When: when}
+		_, err := worktree.Commit("Update", &git.CommitOptions{Author: signature, Committer: signature})
+		assert.NoError(err)
+	}
+
+	first := time.Date(2016, 5, 12, 9, 0, 0, 0, time.UTC)
+	second := time.Date(2016, 6, 1, 10, 0, 0, 0, time.UTC)
+	commit(first, "content/a.md", "content/b.md")
+	commit(second, "content/b.md")
+
+	for name, expected := range map[string]time.Time{
+		"content/a.md": first,
+		"content/b.md": second,
+	} {
+		modified, err := ModifiedFromGit(filepath.Join(dir, name))
+		assert.NoError(err)
+		assert.True(expected.Equal(modified), "%s: %s != %s", name, expected, modified)
+	}
+
+	// The files never committed don't have modified time
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, "content", "c.md"), nil, 0644))
+	modified, err := ModifiedFromGit(filepath.Join(dir, "content", "c.md"))
+	assert.NoError(err)
+	assert.True(modified.IsZero())
+}
@@ -0,0 +1,132 @@
+package file
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/agonzalezro/polo/config"
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+)
+
+// codeBlockOptions are the options given on the info string of a fenced code
+// block, ex: ```go {linenos=table, hl_lines="2 4-6", linenostart=10}
+type codeBlockOptions struct {
+	language          string
+	lineNumbers       bool
+	lineNumbersTable  bool
+	lineNumbersStart  int
+	highlightedRanges [][2]int
+}
+
+var fenceAttributeRe = regexp.MustCompile(`(\w+)\s*=\s*("[^"]*"|\[[^\]]*\]|[^\s,}]+)`)
+
+func parseCodeBlockOptions(info string, c config.Highlight) codeBlockOptions {
+	options := codeBlockOptions{
+		lineNumbers:      c.LineNumbers,
+		lineNumbersStart: 1,
+	}
+
+	info = strings.TrimSpace(info)
+	attributes := ""
+	if i := strings.Index(info, "{"); i >= 0 {
+		attributes = info[i:]
+		info = info[:i]
+	}
+	if fields := strings.Fields(info); len(fields) > 0 {
+		options.language = fields[0]
+	}
+
+	for _, m := range fenceAttributeRe.FindAllStringSubmatch(attributes, -1) {
+		value := strings.Trim(m[2], `"`)
+		switch strings.ToLower(m[1]) {
+		case "linenos":
+			switch value {
+			case "false":
+				options.lineNumbers = false
+			case "table":
+				options.lineNumbers = true
+				options.lineNumbersTable = true
+			default:
+				options.lineNumbers = true
+			}
+		case "linenostart":
+			if n, err := strconv.Atoi(value); err == nil {
+				options.lineNumbersStart = n
+			}
+		case "hl_lines":
+			options.highlightedRanges = parseLineRanges(value)
+		}
+	}
+	return options
+}
+
+// parseLineRanges accepts the lines as "2 4-6" or [2, "4-6"].
+func parseLineRanges(value string) (ranges [][2]int) {
+	value = strings.Trim(value, "[]")
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ' ' || r == ','
+	}) {
+		field = strings.Trim(field, `"`)
+		limits := strings.SplitN(field, "-", 2)
+		start, err := strconv.Atoi(limits[0])
+		if err != nil {
+			continue
+		}
+		end := start
+		if len(limits) == 2 {
+			if end, err = strconv.Atoi(limits[1]); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+func newFormatter(options codeBlockOptions, c config.Highlight) *html.Formatter {
+	return html.New(
+		html.WithClasses(c.CSSClasses),
+		html.WithLineNumbers(options.lineNumbers),
+		html.LineNumbersInTable(options.lineNumbersTable),
+		html.BaseLineNumber(options.lineNumbersStart),
+		html.HighlightLines(options.highlightedRanges),
+	)
+}
+
+// highlight writes the code highlighted as HTML. The info is the string after
+// the opening fence of the code block.
+func highlight(w io.Writer, code, info string, c config.Highlight) error {
+	options := parseCodeBlockOptions(info, c)
+
+	lexer := lexers.Get(options.language)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, code)
+	if err != nil {
+		return err
+	}
+
+	// Don't write anything on errors, so a plain code block can be used instead
+	var buf bytes.Buffer
+	if err := newFormatter(options, c).Format(&buf, styles.Get(c.Style), iterator); err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// HighlightStylesheet writes the CSS needed to highlight the code when the
+// CSS classes are used instead of inline styles.
+func HighlightStylesheet(w io.Writer, c config.Highlight) error {
+	formatter := html.New(html.WithClasses(true))
+	return formatter.WriteCSS(w, styles.Get(c.Style))
+}
@@ -0,0 +1,59 @@
+package file
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/agonzalezro/polo/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodeBlockOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	options := parseCodeBlockOptions(`go {linenos=table, hl_lines="2 4-6", linenostart=10}`, config.Highlight{})
+	assert.Equal("go", options.language)
+	assert.True(options.lineNumbers)
+	assert.True(options.lineNumbersTable)
+	assert.Equal(10, options.lineNumbersStart)
+	assert.Equal([][2]int{{2, 2}, {4, 6}}, options.highlightedRanges)
+
+	options = parseCodeBlockOptions(`python {hl_lines=[1, "3-4"], linenos=false}`, config.Highlight{LineNumbers: true})
+	assert.Equal("python", options.language)
+	assert.False(options.lineNumbers)
+	assert.Equal([][2]int{{1, 1}, {3, 4}}, options.highlightedRanges)
+
+	options = parseCodeBlockOptions("", config.Highlight{LineNumbers: true})
+	assert.Equal("", options.language)
+	assert.True(options.lineNumbers)
+	assert.Equal(1, options.lineNumbersStart)
+}
+
+func TestHighlight(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	err := highlight(&buf, "package main\n", "go", config.Highlight{Style: "monokai", CSSClasses: true})
+	assert.NoError(err)
+	assert.Contains(buf.String(), `<span class="kn">package</span>`)
+
+	buf.Reset()
+	err = highlight(&buf, "package main\n", "go", config.Highlight{Style: "monokai"})
+	assert.NoError(err)
+	assert.NotContains(buf.String(), `class="kn"`)
+	assert.Contains(buf.String(), `style=`)
+}
+
+func TestMarkdownReaderHighlight(t *testing.T) {
+	assert := assert.New(t)
+
+	content := "```go {hl_lines=1}\npackage main\n```\n"
+	for _, engine := range []string{config.BlackfridayEngine, config.CommonMarkEngine} {
+		html := string(MarkdownReader{
+			Config:    config.Markdown{Engine: engine},
+			Highlight: config.Highlight{Enabled: true, CSSClasses: true},
+		}.HTML(content))
+		assert.Contains(html, `<span class="kn">package</span>`, engine)
+		assert.Contains(html, `class="line hl"`, engine)
+	}
+}
@@ -2,10 +2,16 @@ package file
 
 import (
 	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"strings"
 	"time"
+
+	"github.com/agonzalezro/polo/config"
 )
 
 // ParsedFile holds the struct of a file after we parsed the metadata and its
@@ -16,49 +22,132 @@ type ParsedFile struct {
 	Slug    string
 	Content template.HTML
 	Summary template.HTML
+	TOC     TOC
+
+	// PlainText is the content without any markup, ex: for search indexes.
+	PlainText   string
+	WordCount   int
+	ReadingTime int // In minutes
 
 	IsPage   bool
+	IsDraft  bool
 	Category string
 	Tags     []string
 	Date     time.Time
+	Modified time.Time // From the metadata or, optionally, the git history
+	Expires  time.Time // The article is removed from the site after it
+
+	// Params holds the metadata keys unknown by polo, ex: .Article.Params.cover_image
+	Params map[string]interface{}
 
 	// Not to be used by the template
 	rawContent string
 	summary    string
 	status     string // To keep track of the drafts
+	path       string
+	location   *time.Location // For the dates without time zone
+	hash       string         // Of the source, to know when the file changes
+	renderTime time.Duration  // Spent rendering the markdown
 
 	file    *os.File
 	scanner *bufio.Scanner
+	reader  Reader
 }
 
 // New return a new ParsedFile after load it from disk.
-func New(path string) (*ParsedFile, error) {
+func New(path string, config config.Config) (*ParsedFile, error) {
+	reader, ok := ReaderFor(path, config)
+	if !ok {
+		return nil, fmt.Errorf("There is no reader for '%s'", path)
+	}
+
 	pf := ParsedFile{
 		IsPage:   IsPage(path),
 		Category: CategoryFromPath(path),
+		reader:   reader,
+		path:     path,
+		location: config.Location(),
 	}
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	// The file is only needed while parsing, the big sites would run out of
+	// file descriptors otherwise
+	defer file.Close()
 
 	pf.file = file
 	pf.scanner = bufio.NewScanner(file) // We need this to seek after parsing metadata
 
 	if err := pf.parse(); err != nil {
+		parseErr, ok := err.(*ParseError)
+		if !ok {
+			parseErr = &ParseError{Err: err}
+		}
+		parseErr.Path = path
+		return nil, parseErr
+	}
+
+	pf.IsDraft = !pf.IsPublished()
+
+	if _, err := file.Seek(0, 0); err != nil {
 		return nil, err
 	}
+	h := sha1.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return nil, err
+	}
+	pf.hash = hex.EncodeToString(h.Sum(nil))
 
-	pf.Summary = HTML(pf.summaryOrFirstParagraph())
-	pf.Content = HTML(pf.rawContent)
+	if pf.Date.IsZero() && config.DateFromModTime {
+		fileInfo, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		pf.Date = fileInfo.ModTime()
+	}
+
+	if pf.Modified.IsZero() && config.ModifiedFromGit {
+		if pf.Modified, err = ModifiedFromGit(path); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	content := pf.reader.HTML(pf.rawContent)
+	pf.Content, pf.TOC = addHeadingIDs(content, config.HeadingAnchors)
+	pf.Summary = pf.summaryHTML(content, config.SummaryLength)
+	pf.renderTime = time.Since(start)
+
+	pf.PlainText = PlainText(content)
+	pf.WordCount = len(strings.Fields(pf.PlainText))
+	pf.ReadingTime = ReadingTime(pf.WordCount, config.WordsPerMinute)
 	return &pf, nil
 }
 
-// summaryOrFirstParagraph will use the summary from the markdown or generate a new one from the 1st paragraph.
+// summaryHTML renders the summary from the markdown, the content before the
+// summary marker, the first words of the content if length is set, or the 1st
+// paragraph.
+func (f ParsedFile) summaryHTML(content template.HTML, length int) template.HTML {
+	if f.summary == "" {
+		if _, ok := summaryFromMarker(f.rawContent); !ok && length > 0 {
+			return truncateHTML(content, length)
+		}
+	}
+	return f.reader.HTML(f.summaryOrFirstParagraph())
+}
+
+// summaryOrFirstParagraph will use the summary from the markdown, the content
+// until the summary marker (ex: <!--more-->) or generate a new one from the
+// 1st paragraph.
 func (f ParsedFile) summaryOrFirstParagraph() string {
 	summary := f.summary
 
 	if summary == "" {
+		if summary, ok := summaryFromMarker(f.rawContent); ok {
+			return summary
+		}
+
 		// Get the first paragraph
 		for _, summary := range strings.Split(f.rawContent, "\n\n") {
 			if summary != "" {
@@ -70,8 +159,44 @@ func (f ParsedFile) summaryOrFirstParagraph() string {
 	return summary
 }
 
+// Path returns the path of the source file.
+func (f ParsedFile) Path() string {
+	return f.path
+}
+
+// RenderTime returns the time spent rendering the markdown of the file.
+func (f ParsedFile) RenderTime() time.Duration {
+	return f.renderTime
+}
+
+// Hash changes when the source of the file, its path or any of the dates that
+// don't come from the source change.
+func (f ParsedFile) Hash() string {
+	return fmt.Sprintf("%s %s %d %d", f.hash, f.path, f.Date.UnixNano(), f.Modified.UnixNano())
+}
+
+// LastModified returns the modification time of the file, or its date if it
+// wasn't modified, ex: for the <updated> of the feeds.
+func (f ParsedFile) LastModified() time.Time {
+	if f.Modified.After(f.Date) {
+		return f.Modified
+	}
+	return f.Date
+}
+
 // IsPublished will return true if the status is not draft
 // TODO: I wonder if `draft: true` could be a better way of doing this.
 func (f ParsedFile) IsPublished() bool {
 	return strings.ToLower(f.status) != "draft"
 }
+
+// IsScheduled returns true if the file is dated after now.
+func (f ParsedFile) IsScheduled(now time.Time) bool {
+	return f.Date.After(now)
+}
+
+// IsExpired returns true if the file has an expiration date and it's already
+// passed.
+func (f ParsedFile) IsExpired(now time.Time) bool {
+	return !f.Expires.IsZero() && !f.Expires.After(now)
+}
@@ -1,8 +1,14 @@
 package file
 
 import (
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/agonzalezro/polo/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,3 +20,190 @@ func TestIsPublished(t *testing.T) {
 	assert.False(ParsedFile{status: "Draft"}.IsPublished())
 	assert.False(ParsedFile{status: "draft"}.IsPublished())
 }
+
+func TestLastModified(t *testing.T) {
+	assert := assert.New(t)
+
+	date := time.Date(2016, 5, 12, 0, 0, 0, 0, time.UTC)
+	modified := time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(date, ParsedFile{Date: date}.LastModified())
+	assert.Equal(modified, ParsedFile{Date: date, Modified: modified}.LastModified())
+}
+
+func TestSummaryOrFirstParagraph(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("The summary", ParsedFile{summary: "The summary", rawContent: "First\n\nSecond"}.summaryOrFirstParagraph())
+	assert.Equal("First", ParsedFile{rawContent: "First\n\nSecond"}.summaryOrFirstParagraph())
+	assert.Equal("First\n\nSecond\n", ParsedFile{rawContent: "First\n\nSecond\n<!--more-->\nThird"}.summaryOrFirstParagraph())
+	assert.Equal("First\n", ParsedFile{rawContent: "First\n<!-- PELICAN_END_SUMMARY -->\n\nSecond"}.summaryOrFirstParagraph())
+}
+
+func TestSummaryHTML(t *testing.T) {
+	assert := assert.New(t)
+
+	f := ParsedFile{rawContent: "# Title\n\nOne two three four\n", reader: MarkdownReader{}}
+	content := f.reader.HTML(f.rawContent)
+	assert.Equal(template.HTML("<h1>Title</h1>\n\n<p>One two&hellip;</p>"), f.summaryHTML(content, 3))
+	assert.Equal(template.HTML("<h1>Title</h1>\n"), f.summaryHTML(content, 0))
+
+	// The marker is more important than the length
+	f.rawContent = "One two three\n<!--more-->\nfour"
+	assert.Equal(template.HTML("<p>One two three</p>\n"), f.summaryHTML(f.reader.HTML(f.rawContent), 2))
+}
+
+func TestPlainText(t *testing.T) {
+	assert := assert.New(t)
+
+	content := template.HTML("<h2 id=\"a\">Title</h2>\n<p>Fish &amp; <em>chips</em></p><p>and\n  more</p>")
+	assert.Equal("Title Fish & chips and more", PlainText(content))
+}
+
+func TestReadingTime(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(0, ReadingTime(0, 200))
+	assert.Equal(1, ReadingTime(10, 200))
+	assert.Equal(2, ReadingTime(201, 200))
+	assert.Equal(3, ReadingTime(500, 0)) // The default is 200 words per minute
+}
+
+func TestDateAndSlugFromPath(t *testing.T) {
+	assert := assert.New(t)
+
+	date, slug, ok := DateAndSlugFromPath("content/2016-5-2-my-title.markdown", time.UTC)
+	assert.True(ok)
+	assert.Equal(time.Date(2016, 5, 2, 0, 0, 0, 0, time.UTC), date)
+	assert.Equal("my-title", slug)
+
+	_, _, ok = DateAndSlugFromPath("content/my-title.md", time.UTC)
+	assert.False(ok)
+}
+
+func TestIsScheduledAndExpired(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2016, 5, 12, 9, 0, 0, 0, time.UTC)
+
+	assert.False(ParsedFile{}.IsScheduled(now))
+	assert.False(ParsedFile{Date: now}.IsScheduled(now))
+	assert.True(ParsedFile{Date: now.Add(time.Minute)}.IsScheduled(now))
+
+	assert.False(ParsedFile{}.IsExpired(now))
+	assert.False(ParsedFile{Expires: now.Add(time.Minute)}.IsExpired(now))
+	assert.True(ParsedFile{Expires: now}.IsExpired(now))
+}
+
+func TestNewDraft(t *testing.T) {
+	assert := assert.New(t)
+
+	f, err := ioutil.TempFile("", "polo*.md")
+	assert.NoError(err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("Title: My draft\nStatus: draft\n\nContent")
+	assert.NoError(err)
+	assert.NoError(f.Close())
+
+	pf, err := New(f.Name(), config.Config{})
+	assert.NoError(err)
+	assert.True(pf.IsDraft)
+}
+
+func TestNewWithoutMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	f, err := ioutil.TempFile("", "polo*.md")
+	assert.NoError(err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("Note: a\nWarning: b\nmore\n\nBody")
+	assert.NoError(err)
+	assert.NoError(f.Close())
+
+	pf, err := New(f.Name(), config.Config{})
+	assert.NoError(err)
+	assert.Equal("Note: a", pf.Title)
+	assert.Contains(string(pf.Content), "Body")
+	assert.Nil(pf.Params)
+}
+
+func TestNewRSTOverlinedTitle(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "polo")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	p := filepath.Join(dir, "overlined.rst")
+	assert.NoError(ioutil.WriteFile(p, []byte("========\nMy title\n========\n\nThe content"), 0644))
+	pf, err := New(p, config.Config{})
+	if assert.NoError(err) {
+		assert.Equal("My title", pf.Title)
+		assert.Equal("my-title.html", pf.Slug)
+		assert.NotContains(string(pf.Content), "My title")
+		assert.Contains(string(pf.Content), "The content")
+	}
+
+	// Without title the slug is the name of the file
+	p = filepath.Join(dir, "untitled.rst")
+	assert.NoError(ioutil.WriteFile(p, []byte("========\n\nThe content"), 0644))
+	pf, err = New(p, config.Config{})
+	if assert.NoError(err) {
+		assert.Equal("untitled.html", pf.Slug)
+	}
+}
+
+func TestNewHTMLTitleInsideAWrapper(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "polo")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	p := filepath.Join(dir, "wrapped.html")
+	assert.NoError(ioutil.WriteFile(p, []byte("<div class=\"intro\">\n<h1>My title</h1>\n<p>The content</p>\n</div>"), 0644))
+	pf, err := New(p, config.Config{})
+	if assert.NoError(err) {
+		assert.Equal("wrapped.html", pf.Slug)
+		assert.Equal("<div class=\"intro\">\n<h1>My title</h1>\n<p>The content</p>\n</div>\n", pf.rawContent)
+	}
+}
+
+func TestNewRuleAfterTheTitle(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "polo")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{
+		"rule.md":   "# My title\n---\nThe content",
+		"rule.html": "<h1>My title</h1>\n---\nThe content",
+	} {
+		p := filepath.Join(dir, name)
+		assert.NoError(ioutil.WriteFile(p, []byte(content), 0644))
+		pf, err := New(p, config.Config{})
+		if assert.NoError(err, name) {
+			assert.Equal("My title", pf.Title, name)
+			assert.Equal("---\nThe content\n", pf.rawContent, name)
+		}
+	}
+}
+
+func TestNewParseError(t *testing.T) {
+	assert := assert.New(t)
+
+	f, err := ioutil.TempFile("", "polo*.md")
+	assert.NoError(err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("Title: My title\nDate: yesterday\n\nContent")
+	assert.NoError(err)
+	assert.NoError(f.Close())
+
+	_, err = New(f.Name(), config.Config{})
+	parseErr, ok := err.(*ParseError)
+	if assert.True(ok, "%T", err) {
+		assert.Equal(f.Name(), parseErr.Path)
+		assert.Equal(2, parseErr.Line)
+	}
+}
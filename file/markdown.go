@@ -0,0 +1,174 @@
+package file
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/agonzalezro/polo/config"
+	"github.com/russross/blackfriday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// MarkdownReader renders the content with blackfriday or, if it's configured,
+// with a CommonMark compliant engine.
+type MarkdownReader struct {
+	Config    config.Markdown
+	Highlight config.Highlight
+}
+
+// NewMarkdownReader returns a MarkdownReader using the markdown and
+// highlighting configuration of the site.
+func NewMarkdownReader(config config.Config) Reader {
+	return MarkdownReader{Config: config.Markdown, Highlight: config.Highlight}
+}
+
+var markdownTitleRe = regexp.MustCompile(`^#+\s*`)
+
+func (MarkdownReader) Title(line string) (string, bool) {
+	// This is needed to remove markdown syntax before storing values on the structs
+	return markdownTitleRe.ReplaceAllString(line, ""), true
+}
+
+func (r MarkdownReader) HTML(content string) template.HTML {
+	if strings.ToLower(r.Config.Engine) == config.CommonMarkEngine {
+		return r.commonMarkHTML(content)
+	}
+	return r.blackfridayHTML(content)
+}
+
+func (r MarkdownReader) blackfridayHTML(content string) template.HTML {
+	// set up the HTML renderer
+	htmlFlags := 0
+	if !r.Config.NoSmartypants {
+		htmlFlags |= blackfriday.HTML_USE_SMARTYPANTS
+	}
+	if r.Config.Footnotes {
+		htmlFlags |= blackfriday.HTML_FOOTNOTE_RETURN_LINKS
+	}
+	renderer := blackfriday.HtmlRenderer(htmlFlags, "", "")
+	if r.Highlight.Enabled {
+		renderer = highlightingRenderer{Renderer: renderer, config: r.Highlight}
+	}
+
+	// set up the parser
+	extensions := 0
+	extensions |= blackfriday.EXTENSION_NO_INTRA_EMPHASIS
+	extensions |= blackfriday.EXTENSION_TABLES
+	extensions |= blackfriday.EXTENSION_FENCED_CODE
+	extensions |= blackfriday.EXTENSION_AUTOLINK
+	extensions |= blackfriday.EXTENSION_STRIKETHROUGH
+	extensions |= blackfriday.EXTENSION_SPACE_HEADERS
+	if r.Config.Footnotes {
+		extensions |= blackfriday.EXTENSION_FOOTNOTES
+	}
+	if r.Config.DefinitionLists {
+		extensions |= blackfriday.EXTENSION_DEFINITION_LISTS
+	}
+	if r.Config.HeadingIDs {
+		extensions |= blackfriday.EXTENSION_HEADER_IDS
+		extensions |= blackfriday.EXTENSION_AUTO_HEADER_IDS
+	}
+	if r.Config.HardLineBreaks {
+		extensions |= blackfriday.EXTENSION_HARD_LINE_BREAK
+	}
+
+	html := blackfriday.Markdown([]byte(content), renderer, extensions)
+	return template.HTML(html)
+}
+
+func (r MarkdownReader) commonMarkHTML(content string) template.HTML {
+	extensions := []goldmark.Extender{extension.GFM}
+	if !r.Config.NoSmartypants {
+		extensions = append(extensions, extension.Typographer)
+	}
+	if r.Config.Footnotes {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if r.Config.DefinitionLists {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+
+	var parserOptions []parser.Option
+	if r.Config.HeadingIDs {
+		parserOptions = append(parserOptions, parser.WithAutoHeadingID(), parser.WithAttribute())
+	}
+
+	// Raw HTML is allowed, as it is with blackfriday
+	rendererOptions := []renderer.Option{html.WithUnsafe()}
+	if r.Config.HardLineBreaks {
+		rendererOptions = append(rendererOptions, html.WithHardWraps())
+	}
+	if r.Highlight.Enabled {
+		rendererOptions = append(rendererOptions, renderer.WithNodeRenderers(
+			util.Prioritized(highlightingNodeRenderer{config: r.Highlight}, 100)))
+	}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(parserOptions...),
+		goldmark.WithRendererOptions(rendererOptions...),
+	)
+
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(content), &buf); err != nil {
+		// goldmark doesn't fail writing to a buffer, but just in case
+		return template.HTML(template.HTMLEscapeString(content))
+	}
+	return template.HTML(buf.String())
+}
+
+// highlightingRenderer highlights the code blocks rendered by blackfriday.
+type highlightingRenderer struct {
+	blackfriday.Renderer
+	config config.Highlight
+}
+
+func (r highlightingRenderer) BlockCode(out *bytes.Buffer, text []byte, info string) {
+	if err := highlight(out, string(text), info, r.config); err != nil {
+		r.Renderer.BlockCode(out, text, info)
+	}
+}
+
+// highlightingNodeRenderer highlights the fenced code blocks rendered by
+// goldmark.
+type highlightingNodeRenderer struct {
+	config config.Highlight
+}
+
+func (r highlightingNodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r highlightingNodeRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*ast.FencedCodeBlock)
+	var info string
+	if n.Info != nil {
+		info = string(n.Info.Segment.Value(source))
+	}
+
+	var code bytes.Buffer
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+
+	if err := highlight(w, code.String(), info, r.config); err != nil {
+		w.WriteString("<pre><code>")
+		w.WriteString(template.HTMLEscapeString(code.String()))
+		w.WriteString("</code></pre>\n")
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,38 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/agonzalezro/polo/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownReaderOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	content := "Some \"quotes\" and a note[^1].\n\n[^1]: The note.\n"
+
+	for _, engine := range []string{config.BlackfridayEngine, config.CommonMarkEngine} {
+		html := string(MarkdownReader{Config: config.Markdown{Engine: engine}}.HTML(content))
+		assert.Contains(html, "&ldquo;quotes&rdquo;", engine)
+		assert.NotContains(html, "<sup", engine)
+
+		html = string(MarkdownReader{Config: config.Markdown{
+			Engine:        engine,
+			Footnotes:     true,
+			NoSmartypants: true,
+		}}.HTML(content))
+		assert.NotContains(html, "&ldquo;", engine)
+		assert.Contains(html, "<sup", engine)
+	}
+}
+
+func TestMarkdownReaderHeadingIDs(t *testing.T) {
+	assert := assert.New(t)
+
+	content := "# My heading\n"
+	for _, engine := range []string{config.BlackfridayEngine, config.CommonMarkEngine} {
+		html := string(MarkdownReader{Config: config.Markdown{Engine: engine, HeadingIDs: true}}.HTML(content))
+		assert.Contains(html, `id="my-heading"`, engine)
+	}
+}
@@ -2,97 +2,331 @@ package file
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/agonzalezro/polo/utils"
+	"gopkg.in/yaml.v2"
 )
 
-func parseDate(value string) (t time.Time, err error) {
-	acceptedFormats := []string{
+// parseDate parses the dates with or without time zone. The location is used
+// for the dates without it.
+func parseDate(value string, location *time.Location) (t time.Time, err error) {
+	if location == nil {
+		location = time.UTC
+	}
+
+	zonedFormats := []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05 -0700",
+		"2006-01-02 15:04:05 -07:00",
+		"2006-01-02 15:04 -0700",
+		"2006-01-02 15:04 -07:00",
+	}
+	naiveFormats := []string{
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04:05",
 		"2006-01-02 15:04",
 		"2006-1-2 15:04",
 		"2006-01-02",
 		"2006-1-2",
 	}
-	for _, format := range acceptedFormats {
+	for _, format := range zonedFormats {
 		if t, err := time.Parse(format, value); err == nil {
 			return t, nil
 		}
 	}
-	return t, fmt.Errorf("Accepted date/time formats are: %v", acceptedFormats)
+	for _, format := range naiveFormats {
+		if t, err := time.ParseInLocation(format, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return t, fmt.Errorf("Accepted date/time formats are: %v", append(zonedFormats, naiveFormats...))
 }
 
 var NoMetadataFound = errors.New("No metadata found!")
 
+// ParseError is returned when a file can't be parsed. The Line is 0 when the
+// error can't be located, ex: on a malformed TOML block.
+type ParseError struct {
+	Path string
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+// Delimiters of the front matter blocks: YAML for Jekyll, TOML for Hugo and
+// a comment, also YAML, for the HTML files.
+const (
+	yamlFrontMatterDelimiter = "---"
+	tomlFrontMatterDelimiter = "+++"
+
+	commentFrontMatterStart = "<!--"
+	commentFrontMatterEnd   = "-->"
+)
+
+// setMetadata stores the value for one of the known metadata keys on the
+// ParsedFile. It returns false if the key is not known.
+func (pf *ParsedFile) setMetadata(key string, value interface{}) (ok bool, err error) {
+	switch key {
+	case "tags":
+		pf.Tags = append(pf.Tags, parseTags(value)...)
+	case "date":
+		if pf.Date, err = pf.toTime(value); err != nil {
+			return true, err
+		}
+	case "modified", "updated":
+		if pf.Modified, err = pf.toTime(value); err != nil {
+			return true, err
+		}
+	case "expires":
+		if pf.Expires, err = pf.toTime(value); err != nil {
+			return true, err
+		}
+	case "slug":
+		value := toString(value)
+		prefix := "/"
+		if strings.HasPrefix(value, "/") {
+			prefix = "" // Just to be sure that we don't duplicate the /
+		}
+		suffix := ".html"
+		if strings.HasSuffix(value, ".html") || strings.HasSuffix(value, ".html") {
+			suffix = "" // And don't duplicate the html either
+		}
+		pf.Slug = fmt.Sprintf("%s%s%s", prefix, value, suffix)
+	case "status":
+		pf.status = toString(value)
+	case "summary":
+		pf.summary = toString(value)
+	case "author":
+		pf.Author = toString(value)
+	case "title":
+		pf.Title = toString(value)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// toTime returns the dates already parsed by the front matter decoders or
+// parses them.
+func (pf *ParsedFile) toTime(value interface{}) (time.Time, error) {
+	if t, isTime := value.(time.Time); isTime {
+		if t.Location() == time.Local {
+			// The TOML dates without time zone are decoded as local time, but
+			// they are in the time zone of the config
+			location := pf.location
+			if location == nil {
+				location = time.UTC
+			}
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), location)
+		}
+		return t, nil
+	}
+	return parseDate(toString(value), pf.location)
+}
+
+// parseTags accepts the tags as a comma separated string or as a list.
+func parseTags(value interface{}) (tags []string) {
+	var rawTags []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, tag := range v {
+			rawTags = append(rawTags, toString(tag))
+		}
+	case []string:
+		rawTags = v
+	default:
+		rawTags = strings.Split(toString(v), ",")
+	}
+
+	// Remove all the spaces between comma and tag and
+	// add one comma at the beginning and other at the end, this will
+	// make the querying much simpler
+	for _, tag := range rawTags {
+		tags = append(tags, strings.Replace(tag, " ", "", -1))
+	}
+	return tags
+}
+
+// scanUntil returns the lines until the delimiter is found. closed will be
+// false if the end of the file is reached before that.
+func (pf *ParsedFile) scanUntil(delimiter string) (lines []string, closed bool) {
+	for pf.scanner.Scan() {
+		line := pf.scanner.Text()
+		if strings.TrimSpace(line) == delimiter {
+			return lines, true
+		}
+		lines = append(lines, line)
+	}
+	return lines, false
+}
+
+// setParam stores a metadata key unknown by polo so it can be used from the
+// templates.
+func (pf *ParsedFile) setParam(key string, value interface{}) {
+	if pf.Params == nil {
+		pf.Params = make(map[string]interface{})
+	}
+	pf.Params[key] = value
+}
+
+// setMetadataMap sets all the decoded metadata on the ParsedFile, keeping the
+// unknown keys as Params.
+func (pf *ParsedFile) setMetadataMap(metadata map[string]interface{}) error {
+	if len(metadata) == 0 {
+		return NoMetadataFound
+	}
+
+	for key, value := range metadata {
+		key = strings.ToLower(key)
+		ok, err := pf.setMetadata(key, value)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			pf.setParam(key, value)
+		}
+	}
+	return nil
+}
+
+// parseYAMLFrontMatter decodes the YAML enclosed between '---' lines (Jekyll
+// style) or the given end delimiter. If the block is not valid YAML it's
+// parsed line by line as the Pelican metadata.
+func (pf *ParsedFile) parseYAMLFrontMatter(end string) error {
+	lines, closed := pf.scanUntil(end)
+	if !closed {
+		return NoMetadataFound
+	}
+
+	metadata := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &metadata); err != nil {
+		for i, line := range lines {
+			key, value := splitMetadataLine(line)
+			if _, err := pf.setMetadata(key, value); err != nil {
+				return &ParseError{Line: i + 2, Err: err} // After the opening delimiter
+			}
+		}
+		return nil
+	}
+
+	for key, value := range metadata {
+		metadata[key] = normalizeYAML(value)
+	}
+	return pf.setMetadataMap(metadata)
+}
+
+// parseTOMLFrontMatter decodes the TOML enclosed between '+++' lines (Hugo
+// style).
+func (pf *ParsedFile) parseTOMLFrontMatter() error {
+	lines, closed := pf.scanUntil(tomlFrontMatterDelimiter)
+	if !closed {
+		return NoMetadataFound
+	}
+
+	metadata := make(map[string]interface{})
+	if err := toml.Unmarshal([]byte(strings.Join(lines, "\n")), &metadata); err != nil {
+		return fmt.Errorf("Malformed TOML metadata: %v", err)
+	}
+	return pf.setMetadataMap(metadata)
+}
+
+// parseJSONFrontMatter decodes a JSON object at the beginning of the file. The
+// first line of the object was already read by the scanner.
+func (pf *ParsedFile) parseJSONFrontMatter(firstLine string) error {
+	lines := []string{firstLine}
+	for {
+		// The object is complete when it can be decoded
+		if strings.HasSuffix(strings.TrimSpace(lines[len(lines)-1]), "}") {
+			metadata := make(map[string]interface{})
+			if err := json.Unmarshal([]byte(strings.Join(lines, "\n")), &metadata); err == nil {
+				return pf.setMetadataMap(metadata)
+			}
+		}
+
+		if !pf.scanner.Scan() {
+			return NoMetadataFound
+		}
+		lines = append(lines, pf.scanner.Text())
+	}
+}
+
+// metadataKeyRe matches the lines that look like Pelican metadata.
+var metadataKeyRe = regexp.MustCompile(`^:?[\w-]+:`)
+
+// splitMetadataLine returns the lowercased key and the value of a line like
+// `Key: value` or `:key: value`.
+func splitMetadataLine(line string) (key, value string) {
+	// In case that the metadata starts like :date:
+	if strings.HasPrefix(line, ":") {
+		line = line[1:]
+	}
+	metadataSplited := strings.Split(line, ":")
+	key = strings.ToLower(metadataSplited[0])
+	value = strings.Trim(strings.Join(metadataSplited[1:], ":"), " ")
+	return key, value
+}
+
 // parseMetadata sets the metadata on the ParsedFile.
 // If no metadata is found no error is going to be raised.
 func (pf *ParsedFile) parseMetadata() (err error) {
 	var count int
+	var known bool // Unknown keys alone aren't metadata, ex: "Note: ..."
 
 	for pf.scanner.Scan() {
 		count++
 		line := pf.scanner.Text()
 
-		// In case that the metadata starts like :date:
-		if strings.HasPrefix(line, ":") {
-			line = line[1:]
+		if count == 1 {
+			switch trimmed := strings.TrimSpace(line); {
+			case trimmed == yamlFrontMatterDelimiter:
+				// If the metadata is enclosed between lines like this: '---'
+				// (Jekyll style) it's YAML.
+				return pf.parseYAMLFrontMatter(yamlFrontMatterDelimiter)
+			case trimmed == commentFrontMatterStart:
+				return pf.parseYAMLFrontMatter(commentFrontMatterEnd)
+			case trimmed == tomlFrontMatterDelimiter:
+				return pf.parseTOMLFrontMatter()
+			case strings.HasPrefix(trimmed, "{"):
+				return pf.parseJSONFrontMatter(line)
+			}
 		}
-		metadataSplited := strings.Split(line, ":")
-		key := strings.ToLower(metadataSplited[0])
-		value := strings.Trim(strings.Join(metadataSplited[1:], ":"), " ")
 
-		switch key {
-		case "---":
-			// If the metadata is enclosed between lines like this: '---'
-			// (Jekyll style) we need to return after process it.
-			if count > 1 {
-				goto END
-			}
-		case "tags":
-			// Remove all the spaces between comma and tag and
-			// add one comma at the beginning and other at the end, this will
-			// make the querying much simpler
-			for _, tag := range strings.Split(value, ",") {
-				pf.Tags = append(pf.Tags, strings.Replace(tag, " ", "", -1))
-			}
-		case "date":
-			pf.Date, err = parseDate(value)
-			if err != nil {
-				return err
-			}
-		case "slug":
-			prefix := "/"
-			if strings.HasPrefix(value, "/") {
-				prefix = "" // Just to be sure that we don't duplicate the /
-			}
-			suffix := ".html"
-			if strings.HasSuffix(value, ".html") || strings.HasSuffix(value, ".html") {
-				suffix = "" // And don't duplicate the html either
+		key, value := splitMetadataLine(line)
+		ok, err := pf.setMetadata(key, value)
+		if err != nil {
+			return &ParseError{Line: count, Err: err}
+		}
+		known = known || ok
+		if !ok {
+			// Unknown keys are kept as params, anything else is the end of
+			// the metadata
+			if !metadataKeyRe.MatchString(line) {
+				break
 			}
-			pf.Slug = fmt.Sprintf("%s%s%s", prefix, value, suffix)
-		case "status":
-			pf.status = value
-		case "summary":
-			pf.summary = value
-		case "author":
-			pf.Author = value
-		case "title":
-			pf.Title = value
-		default:
-			goto END
+			pf.setParam(key, value)
 		}
 	}
 
-END:
 	// TODO: not the best way to check this. Find a cleaner way.
 	allUnset := func() bool {
-		return (pf.Tags == nil && pf.Date.IsZero() && pf.Slug == "" && pf.status == "" && pf.Summary == "" && pf.Author == "" && pf.Title == "")
+		return (pf.Tags == nil && pf.Date.IsZero() && pf.Slug == "" && pf.status == "" && pf.summary == "" && pf.Author == "" && pf.Title == "")
 	}
-	if count <= 2 && allUnset() {
+	if count <= 2 && allUnset() || !known && pf.Params != nil {
 		return NoMetadataFound
 	}
 	return nil
@@ -108,38 +342,143 @@ func (pf *ParsedFile) parse() error {
 			// Rewind the file and reset the scanner
 			pf.file.Seek(0, 0)
 			pf.scanner = bufio.NewScanner(pf.file)
+			pf.Params = nil
 		default:
 			return err
 		}
 	}
 
-	isFirstLine := true
+	var lines []string
 	for pf.scanner.Scan() {
 		line := pf.scanner.Text()
-		if isFirstLine == true {
-			if line == "" {
-				// Ignore empty lines at the beginning of the file
-				continue
-			}
+		if len(lines) == 0 && line == "" {
+			// Ignore empty lines at the beginning of the file
+			continue
+		}
+		lines = append(lines, line)
+	}
 
-			if pf.Title == "" {
-				// This is needed to remove markdown syntax before storing values on the structs
-				re := regexp.MustCompile("^#+\\s*")
-				pf.Title = re.ReplaceAllString(line, "")
+	if len(lines) > 0 {
+		title, n := heading(pf.reader, lines)
+		if n == 0 && pf.Title == "" {
+			// Without a heading the first line is the title, unless the
+			// metadata has it and then the line is just content
+			if title, ok := pf.reader.Title(lines[0]); ok {
+				pf.setTitle(title)
+				n = 1
 			}
+		} else {
+			pf.setTitle(title)
+		}
+		lines = lines[n:]
+	}
+	for _, line := range lines {
+		pf.rawContent += line + "\n"
+	}
 
-			if pf.Slug == "" {
-				pf.Slug = fmt.Sprintf("%s.html", utils.Slugify(pf.Title))
-			}
+	// Jekyll style filenames have the date and slug, ex: 2016-05-12-my-title.md
+	date, slug, isJekyllFilename := DateAndSlugFromPath(pf.path, pf.location)
+	if pf.Date.IsZero() && isJekyllFilename {
+		pf.Date = date
+	}
 
-			pf.scanner.Scan() // We don't want the title underlining
+	if pf.Slug == "" {
+		if !isJekyllFilename {
+			slug = utils.Slugify(pf.Title)
+		}
+		if slug == "" {
+			// Without title the name of the file is used, it's unique
+			slug = utils.Slugify(strings.TrimSuffix(filepath.Base(pf.path), filepath.Ext(pf.path)))
+		}
+		pf.Slug = fmt.Sprintf("%s.html", slug)
+	}
 
-			isFirstLine = false
-			continue
+	return nil
+}
+
+// setTitle sets the title unless the metadata already did.
+func (pf *ParsedFile) setTitle(title string) {
+	if pf.Title == "" {
+		pf.Title = title
+	}
+}
+
+// heading returns the title of the heading at the beginning of the content and
+// the number of lines it takes, 0 if the content doesn't start with a heading.
+func heading(reader Reader, lines []string) (string, int) {
+	switch reader.(type) {
+	case RSTReader:
+		// The document title can be overlined too
+		if len(lines) >= 2 && isAdornment(lines[0]) && strings.TrimSpace(lines[1]) != "" {
+			title, _ := reader.Title(lines[1])
+			if len(lines) >= 3 && strings.TrimSpace(lines[2]) == strings.TrimSpace(lines[0]) {
+				return title, 3
+			}
+			return title, 2
 		}
+		if len(lines) >= 2 && isAdornment(lines[1]) {
+			title, _ := reader.Title(lines[0])
+			return title, 2
+		}
+	case MarkdownReader:
+		if markdownTitleRe.MatchString(lines[0]) {
+			title, _ := reader.Title(lines[0])
+			return title, 1
+		}
+		// Setext headings are underlined
+		if len(lines) >= 2 && strings.TrimSpace(lines[0]) != "" && isUnderline(lines[1]) {
+			title, _ := reader.Title(lines[0])
+			return title, 2
+		}
+	case HTMLReader:
+		if title, ok := reader.Title(lines[0]); ok {
+			return title, 1
+		}
+	case AsciiDocReader:
+		if asciiDocTitleRe.MatchString(lines[0]) {
+			title, _ := reader.Title(lines[0])
+			return title, 1
+		}
+	}
+	return "", 0
+}
 
-		pf.rawContent += line + "\n"
+// isUnderline returns true if the line is only made of '=' or '-', as the
+// ones used to underline the titles.
+func isUnderline(line string) bool {
+	line = strings.TrimSpace(line)
+	return line != "" && (strings.Trim(line, "=") == "" || strings.Trim(line, "-") == "")
+}
+
+// toString returns the metadata value as a string, no matter the type that
+// the YAML decoder gave to it.
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	default:
+		return fmt.Sprint(v)
 	}
+}
 
-	return nil
+// normalizeYAML converts the nested maps returned by the YAML decoder into
+// map[string]interface{} so they can be easily used from the templates.
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = normalizeYAML(value)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeYAML(v[i])
+		}
+		return v
+	default:
+		return v
+	}
 }
@@ -13,6 +13,7 @@ import (
 func newTestParsedFile(s string) *ParsedFile {
 	return &ParsedFile{
 		scanner: bufio.NewScanner(strings.NewReader(s)),
+		reader:  MarkdownReader{},
 	}
 }
 
@@ -86,7 +87,233 @@ func TestOnlyOneMetadata(t *testing.T) {
 	err := pf.parseMetadata()
 	assert.NoError(err)
 
-	date, err := parseDate(expectedDate)
+	date, err := parseDate(expectedDate, time.UTC)
 	assert.NoError(err)
 	assert.Equal(date, pf.Date)
 }
+
+// Test that the Jekyll metadata is decoded as YAML, supporting lists, quoted
+// strings, multi-line values and keys that polo doesn't know about.
+func TestYAMLFrontMatterParsing(t *testing.T) {
+	assert := assert.New(t)
+
+	content := `---
+title: "Colons: they work now"
+date: 2016-05-12
+tags:
+  - go
+  - jekyll
+summary: >
+  This summary is split
+  in two lines.
+layout: post
+cover:
+  image: /static/cover.png
+---
+
+This is the content`
+	pf := newTestParsedFile(content)
+
+	err := pf.parse()
+	assert.NoError(err)
+
+	expectedDate, _ := parseDate("2016-05-12", time.UTC)
+	assert.Equal("Colons: they work now", pf.Title)
+	assert.Equal(expectedDate, pf.Date)
+	assert.Equal([]string{"go", "jekyll"}, pf.Tags)
+	assert.Equal("This summary is split in two lines.", pf.summary)
+	assert.Equal("post", pf.Params["layout"])
+	assert.Equal(map[string]interface{}{"image": "/static/cover.png"}, pf.Params["cover"])
+
+	// The metadata shouldn't leak into the content, and the title comes from
+	// it so the first line is content too
+	assert.Equal("This is the content\n", pf.rawContent)
+
+	// Unless the first line is a heading
+	pf = newTestParsedFile("---\ntitle: yaml test\n---\n\n# The heading\n\nThis is the content")
+	assert.NoError(pf.parse())
+	assert.Equal("yaml test", pf.Title)
+	assert.Equal("\nThis is the content\n", pf.rawContent)
+}
+
+// Test that the metadata enclosed between '+++' lines is decoded as TOML (Hugo
+// style).
+func TestTOMLFrontMatterParsing(t *testing.T) {
+	assert := assert.New(t)
+
+	content := `+++
+title = "toml test"
+date = 2016-05-12T09:00:00Z
+tags = ["go", "hugo"]
++++
+
+This is the content`
+	pf := newTestParsedFile(content)
+
+	err := pf.parse()
+	assert.NoError(err)
+
+	assert.Equal("toml test", pf.Title)
+	assert.Equal(time.Date(2016, 5, 12, 9, 0, 0, 0, time.UTC), pf.Date.UTC())
+	assert.Equal([]string{"go", "hugo"}, pf.Tags)
+	assert.Equal("This is the content\n", pf.rawContent)
+}
+
+// Test that the TOML dates without time zone use the one of the config.
+func TestTOMLFrontMatterLocalDate(t *testing.T) {
+	assert := assert.New(t)
+
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.NoError(err)
+
+	for content, expected := range map[string]time.Time{
+		"+++\ndate = 2016-05-12T09:00:00\n+++\n\nContent":  time.Date(2016, 5, 12, 9, 0, 0, 0, madrid),
+		"+++\ndate = 2016-05-12\n+++\n\nContent":           time.Date(2016, 5, 12, 0, 0, 0, 0, madrid),
+		"+++\ndate = 2016-05-12T09:00:00Z\n+++\n\nContent": time.Date(2016, 5, 12, 9, 0, 0, 0, time.UTC),
+	} {
+		pf := newTestParsedFile(content)
+		pf.location = madrid
+		assert.NoError(pf.parseMetadata(), content)
+		assert.True(expected.Equal(pf.Date), "%s: %s", content, pf.Date)
+	}
+}
+
+// Test that a JSON object at the beginning of the file is used as metadata.
+func TestJSONFrontMatterParsing(t *testing.T) {
+	assert := assert.New(t)
+
+	content := `{
+  "title": "json test",
+  "tags": ["go", "hugo"],
+  "slug": "json-test"
+}
+
+This is the content`
+	pf := newTestParsedFile(content)
+
+	err := pf.parse()
+	assert.NoError(err)
+
+	assert.Equal("json test", pf.Title)
+	assert.Equal("/json-test.html", pf.Slug)
+	assert.Equal([]string{"go", "hugo"}, pf.Tags)
+	assert.Equal("This is the content\n", pf.rawContent)
+}
+
+// Test that the Pelican metadata keys unknown by polo are stored as Params
+// instead of finishing the metadata parsing.
+func TestPelicanParams(t *testing.T) {
+	assert := assert.New(t)
+
+	content := "Title: params test\nCover_image: /static/cover.png\nSubtitle: the subtitle\nAuthor: Federico\n\nThis is the content"
+	pf := newTestParsedFile(content)
+
+	err := pf.parseMetadata()
+	assert.NoError(err)
+
+	assert.Equal("params test", pf.Title)
+	assert.Equal("Federico", pf.Author)
+	assert.Equal(map[string]interface{}{
+		"cover_image": "/static/cover.png",
+		"subtitle":    "the subtitle",
+	}, pf.Params)
+}
+
+// Test that the lines that look like unknown keys are not metadata unless
+// there is a known key too.
+func TestUnknownKeysAreNotMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	pf := newTestParsedFile("Note: a\nWarning: b\nmore\n\nBody")
+	assert.Equal(NoMetadataFound, pf.parseMetadata())
+
+	pf = newTestParsedFile("Cover: /static/cover.png\nDate: 2016-05-12\n\nBody")
+	assert.NoError(pf.parseMetadata())
+	assert.Equal(map[string]interface{}{"cover": "/static/cover.png"}, pf.Params)
+}
+
+// Test that the metadata of the HTML files can be enclosed in a comment.
+func TestCommentFrontMatterParsing(t *testing.T) {
+	assert := assert.New(t)
+
+	content := "<!--\ntitle: html test\ntags: html, metadata\n-->\n<p>This is the content</p>"
+	pf := newTestParsedFile(content)
+	pf.reader = HTMLReader{}
+
+	err := pf.parse()
+	assert.NoError(err)
+
+	assert.Equal("html test", pf.Title)
+	assert.Equal([]string{"html", "metadata"}, pf.Tags)
+	assert.Equal("<p>This is the content</p>\n", pf.rawContent)
+}
+
+// Test that the date and slug are taken from Jekyll style filenames when the
+// metadata doesn't have them.
+func TestDateAndSlugFromFilename(t *testing.T) {
+	assert := assert.New(t)
+
+	pf := newTestParsedFile("Title: jekyll filename\n\nThis is the content")
+	pf.path = "_posts/2016-05-12-My-Title.md"
+	assert.NoError(pf.parse())
+
+	expectedDate, _ := parseDate("2016-05-12", time.UTC)
+	assert.Equal(expectedDate, pf.Date)
+	assert.Equal("my-title.html", pf.Slug)
+
+	// The metadata is more important
+	pf = newTestParsedFile("Date: 2010-12-03 10:20\nSlug: from-metadata\n\nThis is the content")
+	pf.path = "_posts/2016-05-12-my-title.md"
+	assert.NoError(pf.parse())
+
+	expectedDate, _ = parseDate("2010-12-03 10:20", time.UTC)
+	assert.Equal(expectedDate, pf.Date)
+	assert.Equal("/from-metadata.html", pf.Slug)
+}
+
+func TestParseDate(t *testing.T) {
+	assert := assert.New(t)
+
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.NoError(err)
+
+	for value, expected := range map[string]time.Time{
+		"2016-05-12T09:00:00+02:00":   time.Date(2016, 5, 12, 7, 0, 0, 0, time.UTC),
+		"2016-05-12T09:00:00Z":        time.Date(2016, 5, 12, 9, 0, 0, 0, time.UTC),
+		"2016-05-12 09:00:00 +0200":   time.Date(2016, 5, 12, 7, 0, 0, 0, time.UTC),
+		"2016-05-12 09:00 -07:00":     time.Date(2016, 5, 12, 16, 0, 0, 0, time.UTC),
+		"2016-05-12T09:00:00":         time.Date(2016, 5, 12, 9, 0, 0, 0, madrid),
+		"2016-05-12 09:00:30":         time.Date(2016, 5, 12, 9, 0, 30, 0, madrid),
+		"2016-05-12 09:00":            time.Date(2016, 5, 12, 9, 0, 0, 0, madrid),
+		"2016-5-2":                    time.Date(2016, 5, 2, 0, 0, 0, 0, madrid),
+		"2016-05-12T09:00:00.5+02:00": time.Date(2016, 5, 12, 7, 0, 0, 5e8, time.UTC),
+	} {
+		date, err := parseDate(value, madrid)
+		assert.NoError(err, value)
+		assert.True(expected.Equal(date), "%s: %s != %s", value, expected, date)
+	}
+
+	_, err = parseDate("12/05/2016", madrid)
+	assert.Error(err)
+}
+
+func TestModifiedMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, input := range []string{
+		"Title: My title\nDate: 2016-05-12\nModified: 2016-06-01 10:00\n\nContent",
+		"---\ntitle: My title\ndate: 2016-05-12\nupdated: 2016-06-01 10:00\n---\nContent",
+	} {
+		pf := newTestParsedFile(input)
+		assert.NoError(pf.parse())
+		assert.Equal(time.Date(2016, 6, 1, 10, 0, 0, 0, time.UTC), pf.Modified, input)
+	}
+}
+
+func TestExpiresMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	pf := newTestParsedFile("Title: My title\nExpires: 2016-06-01\n\nContent")
+	assert.NoError(pf.parse())
+	assert.Equal(time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC), pf.Expires)
+}
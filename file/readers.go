@@ -0,0 +1,87 @@
+package file
+
+import (
+	"html/template"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/agonzalezro/polo/config"
+)
+
+// Reader knows how to convert the content of a file format into HTML. The
+// metadata is parsed before by the ParsedFile, so it's shared by all the
+// formats.
+type Reader interface {
+	// Title extracts the title from the first line of the content. If ok is
+	// false the line is not a title and it will be kept as content.
+	Title(line string) (title string, ok bool)
+	// HTML renders the content.
+	HTML(content string) template.HTML
+}
+
+// NewReader returns a Reader configured for the site.
+type NewReader func(config.Config) Reader
+
+var readers = map[string]NewReader{}
+
+// RegisterReader sets the Reader to be used for the files with the given
+// extension, ex: ".md".
+func RegisterReader(extension string, newReader NewReader) {
+	readers[strings.ToLower(extension)] = newReader
+}
+
+// ReaderFor returns the Reader registered for the extension of the path.
+func ReaderFor(path string, config config.Config) (Reader, bool) {
+	newReader, ok := readers[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, false
+	}
+	return newReader(config), true
+}
+
+// IsSupported returns true if there is a Reader registered for the path.
+func IsSupported(path string) bool {
+	_, ok := readers[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+func init() {
+	RegisterReader(".md", NewMarkdownReader)
+	RegisterReader(".markdown", NewMarkdownReader)
+	RegisterReader(".html", NewHTMLReader)
+	RegisterReader(".htm", NewHTMLReader)
+	RegisterReader(".rst", NewRSTReader)
+	RegisterReader(".adoc", NewAsciiDocReader)
+	RegisterReader(".asciidoc", NewAsciiDocReader)
+}
+
+// HTMLReader copies the content as it is, it's useful for articles that were
+// already rendered. The title is taken from a leading <h1> if the metadata
+// doesn't have it.
+type HTMLReader struct{}
+
+// NewHTMLReader returns an HTMLReader, it doesn't need any configuration.
+func NewHTMLReader(config.Config) Reader {
+	return HTMLReader{}
+}
+
+var htmlTitleRe = regexp.MustCompile(`(?i)^\s*<h1[^>]*>(.*)</h1>\s*$`)
+
+func (HTMLReader) Title(line string) (string, bool) {
+	matches := htmlTitleRe.FindStringSubmatch(line)
+	if matches == nil {
+		return "", false
+	}
+	return stripTags(matches[1]), true
+}
+
+func (HTMLReader) HTML(content string) template.HTML {
+	return template.HTML(content)
+}
+
+var tagsRe = regexp.MustCompile(`<[^>]*>`)
+
+func stripTags(s string) string {
+	return strings.TrimSpace(tagsRe.ReplaceAllString(s, ""))
+}
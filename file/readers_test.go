@@ -0,0 +1,131 @@
+package file
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/agonzalezro/polo/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaderFor(t *testing.T) {
+	assert := assert.New(t)
+
+	for path, expected := range map[string]Reader{
+		"content/post.md":       MarkdownReader{Config: config.Markdown{Engine: "commonmark"}},
+		"content/post.markdown": MarkdownReader{Config: config.Markdown{Engine: "commonmark"}},
+		"content/post.html":     HTMLReader{},
+		"content/post.rst":      RSTReader{},
+		"content/post.adoc":     AsciiDocReader{},
+	} {
+		reader, ok := ReaderFor(path, config.Config{Markdown: config.Markdown{Engine: "commonmark"}})
+		assert.True(ok, path)
+		assert.Equal(expected, reader, path)
+	}
+
+	assert.False(IsSupported("content/image.png"))
+}
+
+func TestHTMLReader(t *testing.T) {
+	assert := assert.New(t)
+
+	title, ok := HTMLReader{}.Title("<h1 class=\"title\">My <em>html</em> post</h1>")
+	assert.True(ok)
+	assert.Equal("My html post", title)
+
+	_, ok = HTMLReader{}.Title("<p>This is just content</p>")
+	assert.False(ok)
+
+	content := "<p>This is <strong>already</strong> rendered</p>"
+	assert.Equal(template.HTML(content), HTMLReader{}.HTML(content))
+}
+
+func TestRSTReader(t *testing.T) {
+	assert := assert.New(t)
+
+	content := `Section
+-------
+
+A paragraph with **strong**, *emphasis*, ` + "``code``" + ` and a
+` + "`link <http://example.com>`_" + `.
+
+- First item
+- Second item
+  continues here
+
+Subsection
+~~~~~~~~~~
+
+Some code::
+
+    if a < b {
+    }
+
+.. code-block:: go
+
+    import "fmt"
+
+.. This is a comment
+`
+	expected := `<h2>Section</h2>
+<p>A paragraph with <strong>strong</strong>, <em>emphasis</em>, <code>code</code> and a <a href="http://example.com">link</a>.</p>
+<ul>
+<li>First item</li>
+<li>Second item continues here</li>
+</ul>
+<h3>Subsection</h3>
+<p>Some code:</p>
+<pre><code>if a &lt; b {
+}
+</code></pre>
+<pre><code class="language-go">import &#34;fmt&#34;
+</code></pre>
+`
+	assert.Equal(template.HTML(expected), RSTReader{}.HTML(content))
+}
+
+func TestRSTHeadingLevels(t *testing.T) {
+	assert := assert.New(t)
+
+	var content string
+	for _, c := range []string{"-", "~", "^", "+", "*", "#", "#"} {
+		content += "Section\n" + strings.Repeat(c, 7) + "\n\n"
+	}
+	html := string(RSTReader{}.HTML(content))
+	assert.NotContains(html, "<h7>")
+	assert.Equal(3, strings.Count(html, "<h6>"))
+}
+
+func TestAsciiDocReader(t *testing.T) {
+	assert := assert.New(t)
+
+	title, ok := AsciiDocReader{}.Title("= My AsciiDoc post")
+	assert.True(ok)
+	assert.Equal("My AsciiDoc post", title)
+
+	content := `== Section
+
+A paragraph with *strong*, _emphasis_, ` + "`code`" + ` and a
+http://example.com[link].
+
+. First item
+. Second item
+
+// This is a comment
+[source,go]
+----
+import "fmt"
+----
+`
+	expected := `<h2>Section</h2>
+<p>A paragraph with <strong>strong</strong>, <em>emphasis</em>, <code>code</code> and a <a href="http://example.com">link</a>.</p>
+<ol>
+<li>First item</li>
+<li>Second item</li>
+</ol>
+<pre><code class="language-go">import &#34;fmt&#34;
+</code></pre>
+`
+	assert.Equal(template.HTML(expected), AsciiDocReader{}.HTML(content))
+}
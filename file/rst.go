@@ -0,0 +1,264 @@
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/agonzalezro/polo/config"
+)
+
+// RSTReader renders a subset of reStructuredText: sections, paragraphs,
+// bullet and enumerated lists, literal blocks, code-block directives, block
+// quotes, transitions and the common inline markup.
+type RSTReader struct{}
+
+// NewRSTReader returns a RSTReader, it doesn't need any configuration.
+func NewRSTReader(config.Config) Reader {
+	return RSTReader{}
+}
+
+func (RSTReader) Title(line string) (string, bool) {
+	if isAdornment(line) {
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+func (RSTReader) HTML(content string) template.HTML {
+	r := rstRenderer{lines: strings.Split(content, "\n")}
+	return template.HTML(r.render())
+}
+
+var (
+	rstBulletRe      = regexp.MustCompile(`^[-*+]\s+`)
+	rstEnumeratedRe  = regexp.MustCompile(`^(\d+|#)[.)]\s+`)
+	rstCodeDirective = regexp.MustCompile(`^\.\.\s+(code-block|code|sourcecode)::\s*(\S*)`)
+	rstInlineRe      = regexp.MustCompile("``(.+?)``|\\*\\*(.+?)\\*\\*|\\*(.+?)\\*|`([^`]+?)\\s*<([^>`]+)>`__?|`([^`]+)`_?")
+)
+
+// isAdornment returns true for the lines used to underline (or overline) the
+// sections, ex: '======'.
+func isAdornment(line string) bool {
+	line = strings.TrimRight(line, " ")
+	if len(line) < 2 {
+		return false
+	}
+	c := line[0]
+	if !strings.ContainsRune("=-`:'\"~^_*+#<>.", rune(c)) {
+		return false
+	}
+	return strings.Count(line, string(c)) == len(line)
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+type rstRenderer struct {
+	lines []string
+	i     int
+	buf   bytes.Buffer
+
+	// The heading levels are given by the order in which the adornments
+	// appear on the document.
+	adornments []string
+}
+
+func (r *rstRenderer) headingLevel(adornment string) int {
+	level := 0
+	for i, a := range r.adornments {
+		if a == adornment {
+			level = i + 2 // h1 is the title of the document
+			break
+		}
+	}
+	if level == 0 {
+		r.adornments = append(r.adornments, adornment)
+		level = len(r.adornments) + 1
+	}
+	if level > 6 {
+		level = 6
+	}
+	return level
+}
+
+// indentedBlock returns the following lines that are indented or blank,
+// removing the common indentation.
+func (r *rstRenderer) indentedBlock() []string {
+	var block []string
+	minIndent := -1
+	for ; r.i < len(r.lines); r.i++ {
+		line := r.lines[r.i]
+		if !isBlank(line) && indentation(line) == 0 {
+			break
+		}
+		if !isBlank(line) && (minIndent == -1 || indentation(line) < minIndent) {
+			minIndent = indentation(line)
+		}
+		block = append(block, line)
+	}
+
+	// Leading and trailing blank lines are not part of the block
+	for len(block) > 0 && isBlank(block[0]) {
+		block = block[1:]
+	}
+	for len(block) > 0 && isBlank(block[len(block)-1]) {
+		block = block[:len(block)-1]
+	}
+	for i, line := range block {
+		if len(line) >= minIndent {
+			block[i] = line[minIndent:]
+		} else {
+			block[i] = ""
+		}
+	}
+	return block
+}
+
+// paragraph returns the following lines until a blank one.
+func (r *rstRenderer) paragraph() []string {
+	var lines []string
+	for ; r.i < len(r.lines) && !isBlank(r.lines[r.i]); r.i++ {
+		lines = append(lines, strings.TrimSpace(r.lines[r.i]))
+	}
+	return lines
+}
+
+func (r *rstRenderer) writeLiteral(lines []string, language string) {
+	if language != "" {
+		fmt.Fprintf(&r.buf, "<pre><code class=\"language-%s\">", html.EscapeString(language))
+	} else {
+		r.buf.WriteString("<pre><code>")
+	}
+	r.buf.WriteString(html.EscapeString(strings.Join(lines, "\n")))
+	r.buf.WriteString("\n</code></pre>\n")
+}
+
+func (r *rstRenderer) writeList(tag string, itemRe *regexp.Regexp) {
+	fmt.Fprintf(&r.buf, "<%s>\n", tag)
+	for r.i < len(r.lines) {
+		line := r.lines[r.i]
+		if isBlank(line) {
+			// Lists can have blank lines between the items
+			if r.i+1 < len(r.lines) && itemRe.MatchString(r.lines[r.i+1]) {
+				r.i++
+				continue
+			}
+			break
+		}
+		if !itemRe.MatchString(line) {
+			break
+		}
+
+		item := []string{itemRe.ReplaceAllString(line, "")}
+		r.i++
+		for ; r.i < len(r.lines) && !isBlank(r.lines[r.i]) && indentation(r.lines[r.i]) > 0; r.i++ {
+			item = append(item, strings.TrimSpace(r.lines[r.i]))
+		}
+		fmt.Fprintf(&r.buf, "<li>%s</li>\n", rstInline(strings.Join(item, " ")))
+	}
+	fmt.Fprintf(&r.buf, "</%s>\n", tag)
+}
+
+func (r *rstRenderer) render() string {
+	for r.i < len(r.lines) {
+		line := r.lines[r.i]
+		var next string
+		if r.i+1 < len(r.lines) {
+			next = r.lines[r.i+1]
+		}
+
+		switch {
+		case isBlank(line):
+			r.i++
+		case isAdornment(line) && r.i+2 < len(r.lines) && !isBlank(next) && r.lines[r.i+2] == line:
+			// Section with overline and underline
+			level := r.headingLevel("over" + line[:1])
+			fmt.Fprintf(&r.buf, "<h%d>%s</h%d>\n", level, rstInline(strings.TrimSpace(next)), level)
+			r.i += 3
+		case isAdornment(line) && len(line) >= 4 && isBlank(next):
+			r.buf.WriteString("<hr>\n")
+			r.i++
+		case !isAdornment(line) && isAdornment(next) && indentation(line) == 0 && len(strings.TrimRight(next, " ")) >= len(strings.TrimSpace(line)):
+			level := r.headingLevel(next[:1])
+			fmt.Fprintf(&r.buf, "<h%d>%s</h%d>\n", level, rstInline(strings.TrimSpace(line)), level)
+			r.i += 2
+		case rstCodeDirective.MatchString(line):
+			language := rstCodeDirective.FindStringSubmatch(line)[2]
+			r.i++
+			r.writeLiteral(r.indentedBlock(), language)
+		case strings.HasPrefix(line, ".."):
+			// Comments and unsupported directives are skipped
+			r.i++
+			r.indentedBlock()
+		case rstBulletRe.MatchString(line):
+			r.writeList("ul", rstBulletRe)
+		case rstEnumeratedRe.MatchString(line):
+			r.writeList("ol", rstEnumeratedRe)
+		case indentation(line) > 0:
+			r.buf.WriteString("<blockquote>\n")
+			quote := rstRenderer{lines: r.indentedBlock(), adornments: r.adornments}
+			r.buf.WriteString(quote.render())
+			r.buf.WriteString("</blockquote>\n")
+		default:
+			paragraph := strings.Join(r.paragraph(), " ")
+			isLiteral := strings.HasSuffix(paragraph, "::")
+			if isLiteral {
+				// 'Paragraph::' is rendered as 'Paragraph:' and 'Paragraph ::' or
+				// just '::' are removed.
+				paragraph = strings.TrimSuffix(paragraph, ":")
+				if strings.HasSuffix(paragraph, " :") || paragraph == ":" {
+					paragraph = strings.TrimSpace(strings.TrimSuffix(paragraph, ":"))
+				}
+			}
+			if paragraph != "" {
+				fmt.Fprintf(&r.buf, "<p>%s</p>\n", rstInline(paragraph))
+			}
+			if isLiteral {
+				for r.i < len(r.lines) && isBlank(r.lines[r.i]) {
+					r.i++
+				}
+				r.writeLiteral(r.indentedBlock(), "")
+			}
+		}
+	}
+	return r.buf.String()
+}
+
+// rstInline renders the inline markup: literals (double backquotes),
+// **strong**, *emphasis* and `links <http://example.com>`_.
+func rstInline(s string) string {
+	var buf bytes.Buffer
+	last := 0
+	for _, m := range rstInlineRe.FindAllStringSubmatchIndex(s, -1) {
+		buf.WriteString(html.EscapeString(s[last:m[0]]))
+		last = m[1]
+
+		group := func(n int) string {
+			return html.EscapeString(s[m[2*n]:m[2*n+1]])
+		}
+		switch {
+		case m[2] != -1:
+			fmt.Fprintf(&buf, "<code>%s</code>", group(1))
+		case m[4] != -1:
+			fmt.Fprintf(&buf, "<strong>%s</strong>", group(2))
+		case m[6] != -1:
+			fmt.Fprintf(&buf, "<em>%s</em>", group(3))
+		case m[8] != -1:
+			fmt.Fprintf(&buf, "<a href=\"%s\">%s</a>", group(5), group(4))
+		case m[12] != -1:
+			// Interpreted text and references without target
+			fmt.Fprintf(&buf, "<em>%s</em>", group(6))
+		}
+	}
+	buf.WriteString(html.EscapeString(s[last:]))
+	return buf.String()
+}
@@ -0,0 +1,129 @@
+package file
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// summaryMarkerRe matches the explicit end of the summary: <!--more--> or
+// <!-- PELICAN_END_SUMMARY -->.
+var summaryMarkerRe = regexp.MustCompile(`(?i)<!--\s*(more|PELICAN_END_SUMMARY)\s*-->`)
+
+// summaryFromMarker returns the content before the summary marker.
+func summaryFromMarker(content string) (summary string, ok bool) {
+	loc := summaryMarkerRe.FindStringIndex(content)
+	if loc == nil {
+		return "", false
+	}
+	return content[:loc[0]], true
+}
+
+// voidElements are the HTML elements that don't need to be closed.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// blockElements are the HTML elements that separate words, even without
+// spaces between them.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "li": true, "ul": true, "ol": true, "br": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"pre": true, "blockquote": true, "td": true, "th": true, "tr": true,
+	"dd": true, "dt": true, "hr": true,
+}
+
+func tagName(tag string) string {
+	tag = strings.TrimLeft(tag, "</")
+	end := strings.IndexFunc(tag, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '>' || r == '/'
+	})
+	if end >= 0 {
+		tag = tag[:end]
+	}
+	return strings.ToLower(tag)
+}
+
+// truncateHTML keeps the first words of the HTML content, closing all the tags
+// that were left open.
+func truncateHTML(content template.HTML, words int) template.HTML {
+	var (
+		s         = string(content)
+		buf       bytes.Buffer
+		open      []string
+		count     int
+		inWord    bool
+		truncated bool
+	)
+
+	i := 0
+	for i < len(s) && !truncated {
+		if s[i] == '<' {
+			closing := ">"
+			if strings.HasPrefix(s[i:], "<!--") {
+				closing = "-->"
+			}
+			end := strings.Index(s[i:], closing)
+			if end < 0 {
+				break
+			}
+			tag := s[i : i+end+len(closing)]
+			name := tagName(tag)
+
+			if inWord && blockElements[name] {
+				// Tags as </p> end the word too
+				inWord = false
+				count++
+				if truncated = count >= words; truncated {
+					break
+				}
+			}
+			buf.WriteString(tag)
+			i += len(tag)
+
+			switch {
+			case strings.HasPrefix(tag, "<!"), voidElements[name], strings.HasSuffix(tag, "/>"):
+			case strings.HasPrefix(tag, "</"):
+				for j := len(open) - 1; j >= 0; j-- {
+					if open[j] == name {
+						open = open[:j]
+						break
+					}
+				}
+			default:
+				open = append(open, name)
+			}
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if unicode.IsSpace(r) {
+			if inWord {
+				inWord = false
+				count++
+				if truncated = count >= words; truncated {
+					break
+				}
+			}
+		} else {
+			inWord = true
+		}
+		buf.WriteString(s[i : i+size])
+		i += size
+	}
+
+	if !truncated || stripTags(s[i:]) == "" {
+		return content
+	}
+
+	buf.WriteString("&hellip;")
+	for i := len(open) - 1; i >= 0; i-- {
+		buf.WriteString("</" + open[i] + ">")
+	}
+	return template.HTML(buf.String())
+}
@@ -0,0 +1,26 @@
+package file
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateHTML(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, tc := range []struct {
+		content, expected string
+		words             int
+	}{
+		{"<p>One two three</p>", "<p>One two&hellip;</p>", 2},
+		{"<p>One <em>two three</em> four</p>", "<p>One <em>two&hellip;</em></p>", 2},
+		{"<p>One two</p><p>three</p>", "<p>One two&hellip;</p>", 2},
+		{"<p>One<br>two <img src=\"a.png\"> three</p>", "<p>One<br>two&hellip;</p>", 2},
+		{"<p>One two</p>\n", "<p>One two</p>\n", 2},
+		{"<p>One <!-- a > comment --> two three</p>", "<p>One <!-- a > comment --> two&hellip;</p>", 2},
+	} {
+		assert.Equal(template.HTML(tc.expected), truncateHTML(template.HTML(tc.content), tc.words), tc.content)
+	}
+}
@@ -0,0 +1,103 @@
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strconv"
+
+	"github.com/agonzalezro/polo/utils"
+)
+
+// Heading is an entry of the table of contents.
+type Heading struct {
+	Level    int
+	Title    string
+	ID       string
+	Children TOC
+}
+
+// TOC is the table of contents of a file, built with its headings.
+type TOC []*Heading
+
+// HTML renders the table of contents as nested lists.
+func (toc TOC) HTML() template.HTML {
+	if len(toc) == 0 {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<ul class="toc">`)
+	for _, heading := range toc {
+		fmt.Fprintf(&buf, `<li><a href="#%s">%s</a>`, html.EscapeString(heading.ID), html.EscapeString(heading.Title))
+		buf.WriteString(string(heading.Children.HTML()))
+		buf.WriteString("</li>")
+	}
+	buf.WriteString("</ul>")
+	return template.HTML(buf.String())
+}
+
+var (
+	headingRe   = regexp.MustCompile(`(?s)<h([1-6])([^>]*)>(.*?)</h[1-6]>`)
+	headingIDRe = regexp.MustCompile(`\bid="([^"]*)"`)
+)
+
+// addHeadingIDs gives an unique ID to every heading of the content, adding a
+// link to itself if anchors is true, and returns the table of contents.
+func addHeadingIDs(content template.HTML, anchors bool) (template.HTML, TOC) {
+	var (
+		toc   TOC
+		stack []*Heading // The last heading seen for each level
+		ids   = make(map[string]bool)
+	)
+
+	uniqueID := func(id string) string {
+		if id == "" {
+			id = "section"
+		}
+		unique := id
+		for i := 1; ids[unique]; i++ {
+			unique = fmt.Sprintf("%s-%d", id, i)
+		}
+		ids[unique] = true
+		return unique
+	}
+
+	rendered := headingRe.ReplaceAllStringFunc(string(content), func(match string) string {
+		m := headingRe.FindStringSubmatch(match)
+		level, _ := strconv.Atoi(m[1])
+		attributes, inner := m[2], m[3]
+		title := html.UnescapeString(stripTags(inner))
+
+		var id string
+		if idMatch := headingIDRe.FindStringSubmatch(attributes); idMatch != nil {
+			// Keep the IDs given by the markdown engine
+			id = idMatch[1]
+			ids[id] = true
+		} else {
+			id = uniqueID(utils.Slugify(title))
+			attributes = fmt.Sprintf(` id="%s"%s`, id, attributes)
+		}
+
+		heading := &Heading{Level: level, Title: title, ID: id}
+		for len(stack) > 0 && stack[len(stack)-1].Level >= level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			toc = append(toc, heading)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, heading)
+		}
+		stack = append(stack, heading)
+
+		if anchors {
+			inner += fmt.Sprintf(` <a class="anchor" href="#%s">&para;</a>`, id)
+		}
+		return fmt.Sprintf("<h%d%s>%s</h%d>", level, attributes, inner, level)
+	})
+
+	return template.HTML(rendered), toc
+}
@@ -0,0 +1,50 @@
+package file
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddHeadingIDs(t *testing.T) {
+	assert := assert.New(t)
+
+	content := template.HTML(`<h2>Install</h2>
+<h3>From <em>binary</em></h3>
+<h3>Docker</h3>
+<h2 id="custom">Usage</h2>
+<h3>Docker</h3>
+<h2>Install</h2>`)
+
+	html, toc := addHeadingIDs(content, false)
+	assert.Equal(template.HTML(`<h2 id="install">Install</h2>
+<h3 id="from-binary">From <em>binary</em></h3>
+<h3 id="docker">Docker</h3>
+<h2 id="custom">Usage</h2>
+<h3 id="docker-1">Docker</h3>
+<h2 id="install-1">Install</h2>`), html)
+
+	assert.Len(toc, 3)
+	assert.Equal("Install", toc[0].Title)
+	assert.Len(toc[0].Children, 2)
+	assert.Equal("From binary", toc[0].Children[0].Title)
+	assert.Equal("custom", toc[1].ID)
+	assert.Equal("docker-1", toc[1].Children[0].ID)
+	assert.Empty(toc[2].Children)
+
+	assert.Equal(template.HTML(`<ul class="toc">`+
+		`<li><a href="#install">Install</a><ul class="toc">`+
+		`<li><a href="#from-binary">From binary</a></li>`+
+		`<li><a href="#docker">Docker</a></li></ul></li>`+
+		`<li><a href="#custom">Usage</a><ul class="toc">`+
+		`<li><a href="#docker-1">Docker</a></li></ul></li>`+
+		`<li><a href="#install-1">Install</a></li></ul>`), toc.HTML())
+}
+
+func TestAddHeadingAnchors(t *testing.T) {
+	assert := assert.New(t)
+
+	html, _ := addHeadingIDs(template.HTML("<h2>Install</h2>"), true)
+	assert.Equal(template.HTML(`<h2 id="install">Install <a class="anchor" href="#install">&para;</a></h2>`), html)
+}
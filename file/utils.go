@@ -1,10 +1,16 @@
 package file
 
 import (
+	"html"
 	"html/template"
+	"math"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
-	"github.com/russross/blackfriday"
+	"github.com/agonzalezro/polo/config"
+	"github.com/agonzalezro/polo/utils"
 )
 
 func IsMarkdown(path string) bool {
@@ -15,25 +21,6 @@ func IsPage(path string) bool {
 	return strings.HasPrefix(path, "pages/") || strings.Index(path, "/pages/") > 0
 }
 
-func HTML(content string) template.HTML {
-	// set up the HTML renderer
-	htmlFlags := 0
-	htmlFlags |= blackfriday.HTML_USE_SMARTYPANTS
-	renderer := blackfriday.HtmlRenderer(htmlFlags, "", "")
-
-	// set up the parser
-	extensions := 0
-	extensions |= blackfriday.EXTENSION_NO_INTRA_EMPHASIS
-	extensions |= blackfriday.EXTENSION_TABLES
-	extensions |= blackfriday.EXTENSION_FENCED_CODE
-	extensions |= blackfriday.EXTENSION_AUTOLINK
-	extensions |= blackfriday.EXTENSION_STRIKETHROUGH
-	extensions |= blackfriday.EXTENSION_SPACE_HEADERS
-
-	html := blackfriday.Markdown([]byte(content), renderer, extensions)
-	return template.HTML(html)
-}
-
 func CategoryFromPath(path string) string {
 	splittedPath := strings.Split(path, "/")
 	length := len(splittedPath)
@@ -42,3 +29,44 @@ func CategoryFromPath(path string) string {
 	}
 	return ""
 }
+
+var jekyllFilenameRe = regexp.MustCompile(`^(\d{4}-\d{1,2}-\d{1,2})-(.+)$`)
+
+// DateAndSlugFromPath extracts the date, in the given location, and the slug
+// from Jekyll style filenames, ex: 2016-05-12-my-title.md
+func DateAndSlugFromPath(path string, location *time.Location) (date time.Time, slug string, ok bool) {
+	name := filepath.Base(path)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	matches := jekyllFilenameRe.FindStringSubmatch(name)
+	if matches == nil {
+		return date, "", false
+	}
+
+	if location == nil {
+		location = time.UTC
+	}
+	date, err := time.ParseInLocation("2006-1-2", matches[1], location)
+	if err != nil {
+		return date, "", false
+	}
+	return date, utils.Slugify(matches[2]), true
+}
+
+// PlainText removes the HTML tags and entities of the content.
+func PlainText(content template.HTML) string {
+	text := tagsRe.ReplaceAllString(string(content), " ")
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+// ReadingTime returns the minutes needed to read the words given, never
+// less than one minute if there is something to read.
+func ReadingTime(words, wordsPerMinute int) int {
+	if words == 0 {
+		return 0
+	}
+	if wordsPerMinute <= 0 {
+		wordsPerMinute = config.DefaultWordsPerMinute
+	}
+	return int(math.Ceil(float64(words) / float64(wordsPerMinute)))
+}
@@ -0,0 +1,216 @@
+package site
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/agonzalezro/polo/file"
+)
+
+// manifestPath is where the build cache is stored, relative to the output.
+const manifestPath = ".polo-manifest.json"
+
+// manifest is the content of the manifest file.
+type manifest struct {
+	// Preview is true for the builds with the drafts or the future articles
+	Preview bool              `json:"preview"`
+	Outputs map[string]string `json:"outputs"` // The inputs hash by output path
+	// Previews are the outputs of the preview builds before this one that
+	// were not written again, ex: the renamed or deleted drafts
+	Previews []string `json:"previews,omitempty"`
+}
+
+// buildCache knows the hash of the inputs used to render every output of the
+// last build, so the outputs whose inputs didn't change aren't written again.
+type buildCache struct {
+	output string
+	force  bool
+
+	// siteHash has the inputs shared by all the outputs: config, templates,
+	// pages, tags and categories.
+	siteHash string
+
+	mux      *sync.Mutex
+	previous manifest
+	current  manifest
+}
+
+func newBuildCache(s Site) (*buildCache, error) {
+	config, err := json.Marshal(s.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	templatesMux.Lock()
+	inputs := []interface{}{config, templatesSum, s.Context.Tags, s.Context.Categories}
+	templatesMux.Unlock()
+	for _, page := range s.Context.Pages {
+		inputs = append(inputs, page.Hash())
+	}
+
+	c := &buildCache{
+		output:   s.output,
+		force:    s.options.Force,
+		siteHash: hash(inputs...),
+		mux:      &sync.Mutex{},
+		current: manifest{
+			Preview: s.options.Drafts || s.options.BuildFuture,
+			Outputs: make(map[string]string),
+		},
+	}
+
+	b, err := ioutil.ReadFile(path.Join(s.output, manifestPath))
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &c.previous); err != nil {
+		// A broken manifest is just like not having it
+		c.previous = manifest{}
+	}
+	return c, nil
+}
+
+// hash returns the SHA1 of the inputs.
+func hash(inputs ...interface{}) string {
+	h := sha1.New()
+	for _, input := range inputs {
+		fmt.Fprintf(h, "%s\n", input)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// articlesHash is used for the outputs listing several articles.
+func articlesHash(articles []file.ParsedFile) string {
+	var hashes []interface{}
+	for _, article := range articles {
+		hashes = append(hashes, article.Hash())
+	}
+	return hash(hashes...)
+}
+
+// key returns the hash of all the inputs of an output.
+func (c *buildCache) key(templateName string, inputs ...interface{}) string {
+	return hash(append([]interface{}{c.siteHash, templateName}, inputs...)...)
+}
+
+// isFresh returns true if the output exists and it was rendered with the
+// same inputs.
+func (c *buildCache) isFresh(relativePath, key string) bool {
+	if c.force {
+		return false
+	}
+	relativePath = cleanPath(relativePath)
+
+	c.mux.Lock()
+	previous, ok := c.previous.Outputs[relativePath]
+	c.mux.Unlock()
+	if !ok || previous != key {
+		return false
+	}
+
+	_, err := os.Stat(path.Join(c.output, relativePath))
+	return err == nil
+}
+
+// set records the inputs used to render the output.
+func (c *buildCache) set(relativePath, key string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.current.Outputs[cleanPath(relativePath)] = key
+}
+
+// written returns the paths of all the outputs of this build.
+func (c *buildCache) written() map[string]string {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	written := make(map[string]string, len(c.current.Outputs))
+	for p, key := range c.current.Outputs {
+		written[p] = key
+	}
+	return written
+}
+
+// stalePreviews returns the outputs of the last build that were not written by
+// this one, if the last build was a preview and this one is not. They could be
+// drafts or future articles that must not be published.
+func (c *buildCache) stalePreviews() []string {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.current.Preview {
+		return nil
+	}
+	return c.previewOutputs()
+}
+
+// previewOutputs returns the outputs of the last preview builds not written by
+// this one. Without lock.
+func (c *buildCache) previewOutputs() []string {
+	if !c.previous.Preview {
+		return nil
+	}
+
+	stale := make(map[string]bool)
+	for p := range c.previous.Outputs {
+		stale[p] = true
+	}
+	for _, p := range c.previous.Previews {
+		stale[p] = true
+	}
+
+	var outputs []string
+	for p := range stale {
+		if _, ok := c.current.Outputs[p]; !ok {
+			outputs = append(outputs, p)
+		}
+	}
+	sort.Strings(outputs)
+	return outputs
+}
+
+// cleanPath normalizes the paths relative to the output, ex: the slugs
+// starting with "/".
+func cleanPath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+// save writes the manifest with the outputs of this build.
+func (c *buildCache) save() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	// The next build that is not a preview removes all of them
+	if c.current.Preview {
+		c.current.Previews = c.previewOutputs()
+	}
+
+	b, err := json.MarshalIndent(c.current, "", "  ")
+	if err != nil {
+		return err
+	}
+	// The manifest could be a hard link to the one of the last build
+	p := path.Join(c.output, manifestPath)
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	return syncAndClose(f)
+}
@@ -0,0 +1,79 @@
+package site
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BuildError is an error loading a source file or writing an output of the
+// site. The fields that are not known are empty.
+type BuildError struct {
+	Output   string // Relative to the output folder
+	Source   string
+	Template string
+	Err      error
+}
+
+func (e *BuildError) Error() string {
+	if e.Output == "" {
+		// The errors of the sources already have their path
+		return e.Err.Error()
+	}
+
+	var details []string
+	if e.Source != "" {
+		details = append(details, "source "+e.Source)
+	}
+	if e.Template != "" {
+		details = append(details, "template "+e.Template)
+	}
+	if len(details) == 0 {
+		return fmt.Sprintf("%s: %v", e.Output, e.Err)
+	}
+	return fmt.Sprintf("%s (%s): %v", e.Output, strings.Join(details, ", "), e.Err)
+}
+
+// MarshalJSON is used for the machine readable reports.
+func (e *BuildError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Output   string `json:"output,omitempty"`
+		Source   string `json:"source,omitempty"`
+		Template string `json:"template,omitempty"`
+		Error    string `json:"error"`
+	}{e.Output, e.Source, e.Template, e.Err.Error()})
+}
+
+// BuildErrors are all the errors of a build.
+type BuildErrors []*BuildError
+
+func (errs BuildErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	if len(errs) == 1 {
+		return messages[0]
+	}
+	return fmt.Sprintf("%d errors:\n%s", len(errs), strings.Join(messages, "\n"))
+}
+
+// sort orders the errors by source and output, the goroutines writing the
+// outputs can finish in any order.
+func (errs BuildErrors) sort() {
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].Source != errs[j].Source {
+			return errs[i].Source < errs[j].Source
+		}
+		return errs[i].Output < errs[j].Output
+	})
+}
+
+// toBuildError keeps the structured errors and wraps the other ones.
+func toBuildError(err error) *BuildError {
+	if buildErr, ok := err.(*BuildError); ok {
+		return buildErr
+	}
+	return &BuildError{Err: err}
+}
@@ -1,12 +1,14 @@
 package site
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/agonzalezro/polo/config"
 	"github.com/agonzalezro/polo/context"
@@ -36,19 +38,38 @@ type Siteable interface {
 	Write() error
 }
 
+// Options changes how the site is built, they are usually given from the
+// command line.
+type Options struct {
+	// BuildFuture includes the articles dated in the future.
+	BuildFuture bool
+	// Force writes all the outputs, even the ones that didn't change since
+	// the last build.
+	Force bool
+	// Drafts includes the drafts, except on the feeds, to preview them.
+	Drafts bool
+	// Jobs is the number of outputs written at the same time, one per CPU
+	// if it isn't positive.
+	Jobs int
+}
+
 type Site struct {
 	port              int
 	source, output    string
 	templatesBasePath string
+	options           Options
 
 	slugs map[string]bool
 	mux   *sync.Mutex
+	cache *buildCache // Only while writing
+	clean bool        // Write on an empty folder
+	stats *buildStats
 
 	Config  config.Config
 	Context *context.Context
 }
 
-func New(source, output, configPath, templatesBasePath string) (*Site, error) {
+func New(source, output, configPath, templatesBasePath string, options Options) (*Site, error) {
 	config, err := config.New(configPath)
 	if err != nil {
 		return nil, err
@@ -58,9 +79,11 @@ func New(source, output, configPath, templatesBasePath string) (*Site, error) {
 		source:            source,
 		output:            output,
 		templatesBasePath: templatesBasePath,
+		options:           options,
 		Config:            *config,
 		Context:           context.New(*config),
 		mux:               &sync.Mutex{},
+		stats:             &buildStats{},
 	}
 
 	return &s, s.Load()
@@ -81,14 +104,15 @@ func (s *Site) parse(path string, fileInfo os.FileInfo, err error) error {
 		return err
 	}
 
-	if fileInfo.Mode().IsDir() || !file.IsMarkdown(path) {
+	if fileInfo.Mode().IsDir() || !file.IsSupported(path) {
 		return nil
 	}
 
-	file, err := file.New(path)
+	file, err := file.New(path, s.Config)
 	if err != nil {
 		return err
 	}
+	s.stats.addSource(*file)
 
 	if _, present := s.slugs[file.Slug]; present {
 		return fmt.Errorf("The slug '%s' already exists!", file.Slug)
@@ -102,9 +126,10 @@ func (s *Site) parse(path string, fileInfo os.FileInfo, err error) error {
 	}
 
 	// If it's not a page, it's an article
-	if file.IsPublished() {
-		s.Context.Articles = append(s.Context.Articles, *file)
+	if !s.isVisible(*file) {
+		return nil
 	}
+	s.Context.Articles = append(s.Context.Articles, *file)
 
 	// Just supported on Articles
 	if len(file.Tags) > 0 {
@@ -114,190 +139,314 @@ func (s *Site) parse(path string, fileInfo os.FileInfo, err error) error {
 	return nil
 }
 
+// isVisible returns false for the expired articles, and for the drafts and the
+// ones scheduled for the future unless they are explicitly built.
+func (s *Site) isVisible(article file.ParsedFile) bool {
+	now := time.Now()
+	if article.IsDraft && !s.options.Drafts || article.IsExpired(now) {
+		return false
+	}
+	return s.options.BuildFuture || !article.IsScheduled(now)
+}
+
+// Load parses all the content. All the files are parsed even if some of them
+// fail, to report all the errors at once.
 func (s *Site) Load() error {
-	if err := filepath.Walk(s.source, s.parse); err != nil {
+	start := time.Now()
+	s.stats.startLoad()
+
+	var errs BuildErrors
+	err := filepath.Walk(s.source, func(path string, fileInfo os.FileInfo, err error) error {
+		if err := s.parse(path, fileInfo, err); err != nil {
+			errs = append(errs, &BuildError{Source: path, Err: err})
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
+	if len(errs) > 0 {
+		errs.sort()
+		return errs
+	}
+
 	// Sort the articles after we got them
 	sort.Sort(s.Context)
+	s.Context.Paginate()
+	s.stats.endLoad(s.Context, time.Since(start))
 	return nil
 }
 
+// Rebuild loads all the content again from scratch and writes the site, so the
+// files added, modified or deleted since the last build are taken into account.
+func (s *Site) Rebuild() error {
+	slugs, c := s.slugs, s.Context
+	s.slugs = nil
+	s.Context = context.New(s.Config)
+
+	if err := s.Load(); err != nil {
+		// Keep the content of the last build
+		s.slugs, s.Context = slugs, c
+		return err
+	}
+	return s.Write()
+}
+
 func (s Site) Write() error {
-	var wg sync.WaitGroup
+	start := time.Now()
+	s.stats.startWrite()
+	defer func() { s.stats.endWrite(time.Since(start)) }()
 
-	errCh := make(chan error, 1024) // TODO: 1024 should be more than enough for the errors
+	// Don't write anything if the templates are broken
+	if _, err := s.getTemplates(); err != nil {
+		return err
+	}
+
+	// The site is written on a staging folder, s is a copy so all the writers
+	// will use it as output.
+	output := s.output
+	staging, err := s.stage()
+	if err != nil {
+		return err
+	}
+	s.output = staging
+
+	cache, err := newBuildCache(s)
+	if err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+	s.cache = cache
 
-	s.writeIndexes(&wg, errCh)
-	s.writeFeeds(&wg, errCh)
-	s.writeArticles(&wg, errCh)
-	s.writePages(&wg, errCh)
+	sc := newScheduler(s.options.Jobs)
+	s.writeIndexes(sc)
+	s.writeFeeds(sc)
+	s.writeArticles(sc)
+	s.writePages(sc)
 
 	if s.Config.ShowArchive {
-		s.writeArchive(&wg, errCh)
+		s.writeArchive(sc)
 	}
 	if s.Config.ShowCategories {
-		s.writeCategories(&wg, errCh)
+		s.writeCategories(sc)
 	}
 	if s.Config.ShowTags {
-		s.writeTags(&wg, errCh)
+		s.writeTags(sc)
 	}
+	if s.Config.Highlight.Enabled && s.Config.Highlight.CSSClasses {
+		s.writeHighlightStylesheet(sc)
+	}
+	errs := sc.wait()
 
-	wg.Wait()
+	// The drafts and the future articles of a preview aren't published later
+	if len(errs) == 0 {
+		if err := s.removeOutputs(s.cache.stalePreviews()); err != nil {
+			errs = append(errs, toBuildError(err))
+		}
+	}
 
-	for {
-		select {
-		case err := <-errCh:
-			// I could append errors here but it isn't worthy
-			return err
-		default:
-			return nil
+	if err := s.cache.save(); err != nil {
+		errs = append(errs, toBuildError(err))
+	}
+
+	// The outputs of a build with errors are not complete
+	if s.Config.Prune && len(errs) == 0 {
+		if err := s.prune(s.cache.written()); err != nil {
+			errs = append(errs, toBuildError(err))
 		}
 	}
+
+	if len(errs) > 0 {
+		os.RemoveAll(staging)
+		errs.sort()
+		return errs
+	}
+	return swap(output, staging)
 }
 
-func (s Site) writef(relativePath string, templateName string, c context.Context) error {
-	tpl, err := s.getTemplate(templateName)
-	if err != nil {
-		return err
+// writef renders the template on the relative path of the output, unless it
+// was already rendered with the same inputs on the last build. It returns
+// false in that case.
+func (s Site) writef(relativePath string, templateName string, c context.Context, inputs ...interface{}) (bool, error) {
+	source := c.Article.Path()
+	if source == "" {
+		source = c.Page.Path()
 	}
 
-	// Ensure absolute path exists
-	err = s.mkdirP(relativePath)
+	written, err := s.write(relativePath, templateName, source, c, inputs...)
 	if err != nil {
-		return err
+		return false, &BuildError{Output: cleanPath(relativePath), Source: source, Template: templateName, Err: err}
 	}
+	return written, nil
+}
 
-	f, err := os.Create(path.Join(s.output, relativePath))
+func (s Site) write(relativePath, templateName, source string, c context.Context, inputs ...interface{}) (bool, error) {
+	key := s.cache.key(templateName, inputs...)
+	if s.cache.isFresh(relativePath, key) {
+		s.cache.set(relativePath, key)
+		s.stats.addUpToDate()
+		return false, nil
+	}
+
+	tpl, err := s.getTemplate(templateName)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return tpl.ExecuteTemplate(f, "base", c)
+	// The template is rendered in memory to time it apart from the disk
+	start := time.Now()
+	var b bytes.Buffer
+	if err := tpl.ExecuteTemplate(&b, "base", c); err != nil {
+		return false, err
+	}
+	rendered := time.Since(start)
+
+	start = time.Now()
+	if err := s.writeFile(relativePath, b.Bytes()); err != nil {
+		return false, err
+	}
+	output := OutputStats{Output: cleanPath(relativePath), Source: source, Template: templateName, Bytes: int64(b.Len())}
+	s.stats.addOutput(output, rendered, time.Since(start))
+
+	s.cache.set(relativePath, key)
+	return true, nil
 }
 
-func (s Site) writeIndexes(wg *sync.WaitGroup, errCh chan<- error) {
+func (s Site) writeIndexes(sc *scheduler) {
 	for i := 1; i <= s.Context.NumberOfPages(); i++ {
-		wg.Add(1)
-		go func(page int) {
-			defer wg.Done()
+		page := i
 
-			indexFile := "index.html"
-			if page > 1 {
-				indexFile = fmt.Sprintf("index%d.html", page)
-			}
+		indexFile := "index.html"
+		if page > 1 {
+			indexFile = fmt.Sprintf("index%d.html", page)
+		}
 
+		sc.add(indexFile, func() (bool, error) {
 			c := s.Context.Copy()
 			c.Articles = c.FilterByPage(page)
 
 			c.CurrentPage = page
 
-			if err := s.writef(indexFile, indexTemplate, *c); err != nil {
-				errCh <- err
-			}
-		}(i)
+			return s.writef(indexFile, indexTemplate, *c, articlesHash(c.Articles), page, c.NumberOfPages())
+		})
 	}
 }
 
-func (s Site) writeFeeds(wg *sync.WaitGroup, errCh chan<- error) {
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
+func (s Site) writeFeeds(sc *scheduler) {
+	sc.add(atomPath, func() (bool, error) {
+		c := s.Context.Copy()
 
-		limit := len(s.Context.Articles)
+		// The drafts are never published on the feeds
+		c.Articles = nil
+		for _, article := range s.Context.Articles {
+			if !article.IsDraft {
+				c.Articles = append(c.Articles, article)
+			}
+		}
+
+		limit := len(c.Articles)
 		if limit > 10 {
 			limit = 10
 		}
-
-		c := s.Context.Copy()
 		c.Articles = c.Articles[0:limit]
 
 		// TODO: just ATOM feeds, not RSS unless somebody needs it and he/she is willing to implement it :)
-		if err := s.writef(atomPath, atomTemplate, *c); err != nil {
-			errCh <- err
-		}
-	}()
+		return s.writef(atomPath, atomTemplate, *c, articlesHash(c.Articles))
+	})
 }
 
-func (s Site) writeArticles(wg *sync.WaitGroup, errCh chan<- error) {
+// writeArticles writes every article. They depend on all the articles too, the
+// templates can list them, ex: the recent posts or the previous and next ones.
+func (s Site) writeArticles(sc *scheduler) {
+	articles := articlesHash(s.Context.Articles)
 	for _, article := range s.Context.Articles {
-		wg.Add(1)
-		go func(article file.ParsedFile) {
-			defer wg.Done()
+		article := article
 
+		p := path.Join(articlesPrefixPath, article.Slug)
+		sc.add(p, func() (bool, error) {
 			c := s.Context.Copy()
 			c.Article = article
 
-			p := path.Join(articlesPrefixPath, article.Slug)
-			if err := s.writef(p, articleTemplate, *c); err != nil {
-				errCh <- err
-			}
-		}(article)
+			return s.writef(p, articleTemplate, *c, article.Hash(), articles)
+		})
 	}
 }
 
-func (s Site) writePages(wg *sync.WaitGroup, errCh chan<- error) {
+// writePages writes every page. As the articles, they depend on all the
+// articles.
+func (s Site) writePages(sc *scheduler) {
+	articles := articlesHash(s.Context.Articles)
 	for _, page := range s.Context.Pages {
-		wg.Add(1)
-		go func(page file.ParsedFile) {
-			defer wg.Done()
+		page := page
 
+		p := path.Join(pagesPrefixPath, page.Slug)
+		sc.add(p, func() (bool, error) {
 			c := s.Context.Copy()
 			c.Page = page
 
-			p := path.Join(pagesPrefixPath, page.Slug)
-			if err := s.writef(p, pageTemplate, *c); err != nil {
-				errCh <- err
-			}
-		}(page)
+			return s.writef(p, pageTemplate, *c, page.Hash(), articles)
+		})
 	}
 }
 
-func (s Site) writeArchive(wg *sync.WaitGroup, errCh chan<- error) {
-	wg.Add(1)
-	defer wg.Done()
-
-	if err := s.writef(archivePath, archiveTemplate, *s.Context); err != nil {
-		errCh <- err
-	}
+func (s Site) writeArchive(sc *scheduler) {
+	sc.add(archivePath, func() (bool, error) {
+		return s.writef(archivePath, archiveTemplate, *s.Context, articlesHash(s.Context.Articles))
+	})
 }
 
-func (s Site) writeCategories(wg *sync.WaitGroup, errCh chan<- error) {
+func (s Site) writeCategories(sc *scheduler) {
 	for _, category := range s.Context.Categories {
-		wg.Add(1)
-		go func(category string) {
-			defer wg.Done()
+		category := category
 
+		p := fmt.Sprintf(categoryPathFormater, category)
+		sc.add(p, func() (bool, error) {
 			c := s.Context.Copy()
 			c.Articles = c.FilterByCategory(category)
 			c.Category = category
 
-			p := fmt.Sprintf(categoryPathFormater, category)
-			if err := s.writef(p, categoryTemplate, *c); err != nil {
-				errCh <- err
-			}
-		}(category)
+			return s.writef(p, categoryTemplate, *c, articlesHash(c.Articles), category)
+		})
 	}
 }
 
-func (s Site) writeTags(wg *sync.WaitGroup, errCh chan<- error) {
+func (s Site) writeTags(sc *scheduler) {
 	for _, tag := range s.Context.Tags {
-		wg.Add(1)
-		go func(tag string) {
-			defer wg.Done()
+		tag := tag
 
+		p := fmt.Sprintf(tagPathFormater, tag)
+		sc.add(p, func() (bool, error) {
 			c := s.Context.Copy()
 			c.Articles = c.FilterByTag(tag)
 			c.Tag = tag
 
-			p := fmt.Sprintf(tagPathFormater, tag)
-			if err := s.writef(p, tagTemplate, *c); err != nil {
-				errCh <- err
-			}
-		}(tag)
+			return s.writef(p, tagTemplate, *c, articlesHash(c.Articles), tag)
+		})
 	}
 }
 
+// writeHighlightStylesheet writes the CSS for the highlighted code blocks.
+func (s Site) writeHighlightStylesheet(sc *scheduler) {
+	p := s.Config.Highlight.Stylesheet
+	sc.add(p, func() (bool, error) {
+		start := time.Now()
+		var b bytes.Buffer
+		if err := file.HighlightStylesheet(&b, s.Config.Highlight); err != nil {
+			return false, &BuildError{Output: p, Err: err}
+		}
+		rendered := time.Since(start)
+
+		start = time.Now()
+		if err := s.writeFile(p, b.Bytes()); err != nil {
+			return false, &BuildError{Output: p, Err: err}
+		}
+		s.stats.addOutput(OutputStats{Output: cleanPath(p), Bytes: int64(b.Len())}, rendered, time.Since(start))
+
+		s.cache.set(p, s.cache.key("highlight"))
+		return true, nil
+	})
+}
+
 // mkdirP assures that the full path exists. It mimics `mkdir -p`
 func (s *Site) mkdirP(elem ...string) error {
 	s.mux.Lock()
@@ -0,0 +1,507 @@
+package site
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSite is a site with its source and output on temporary folders.
+type testSite struct {
+	*Site
+	t    *testing.T
+	root string
+}
+
+func newTestSite(t *testing.T, posts map[string]string) *testSite {
+	root, err := ioutil.TempDir("", "polo")
+	require.NoError(t, err)
+
+	ts := &testSite{t: t, root: root}
+	require.NoError(t, os.Mkdir(ts.path("content"), 0755))
+	require.NoError(t, os.Mkdir(ts.path("output"), 0755))
+	ts.writeFile("config.json", `{"title": "Test", "paginationSize": 10}`)
+	for name, content := range posts {
+		ts.writePost(name, content)
+	}
+
+	ts.Site, err = New(ts.path("content"), ts.path("output"), ts.path("config.json"), ".", Options{})
+	require.NoError(t, err)
+	require.NoError(t, ts.Write())
+	return ts
+}
+
+func (ts *testSite) path(elem ...string) string {
+	return filepath.Join(append([]string{ts.root}, elem...)...)
+}
+
+func (ts *testSite) writeFile(name, content string) {
+	require.NoError(ts.t, ioutil.WriteFile(ts.path(name), []byte(content), 0644))
+}
+
+func (ts *testSite) writePost(name, content string) {
+	ts.writeFile(filepath.Join("content", name), content)
+}
+
+func (ts *testSite) readOutput(name string) string {
+	b, err := ioutil.ReadFile(ts.path("output", name))
+	require.NoError(ts.t, err)
+	return string(b)
+}
+
+// copyTemplates copies the content templates of the repo to dst. They are
+// never linked, the tests change them.
+func (ts *testSite) copyTemplates(dst string) {
+	src := filepath.Join("..", TemplatesRelativePath, "body", "content")
+	err := filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, relativePath), 0755)
+		}
+		return copyFile(p, filepath.Join(dst, relativePath), 0644)
+	})
+	require.NoError(ts.t, err)
+}
+
+func (ts *testSite) Close() {
+	os.RemoveAll(ts.root)
+}
+
+func TestRebuildAddedPost(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md": "Date: 2016-05-12\n\n# First post\n\nThe first content",
+	})
+	defer ts.Close()
+
+	ts.writePost("second.md", "Date: 2016-05-13\nTags: new\n\n# Second post\n\nThe second content")
+	assert.NoError(ts.Rebuild())
+
+	assert.Len(ts.Context.Articles, 2)
+	assert.Equal([]string{"new"}, ts.Context.Tags)
+	assert.Contains(ts.readOutput("second-post.html"), "The second content")
+	assert.Contains(ts.readOutput("index.html"), "Second post")
+	assert.Contains(ts.readOutput("index.html"), "First post")
+}
+
+func TestRebuildEditedPost(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md": "Slug: first\nDate: 2016-05-12\n\n# First post\n\nThe old content",
+	})
+	defer ts.Close()
+	assert.Contains(ts.readOutput("first.html"), "The old content")
+
+	// The slug is the same, so it must not be reported as duplicated
+	ts.writePost("first.md", "Slug: first\nDate: 2016-05-12\n\n# Edited post\n\nThe new content")
+	assert.NoError(ts.Rebuild())
+
+	assert.Len(ts.Context.Articles, 1)
+	assert.Contains(ts.readOutput("first.html"), "The new content")
+	assert.NotContains(ts.readOutput("first.html"), "The old content")
+	assert.Contains(ts.readOutput("index.html"), "Edited post")
+}
+
+func TestRebuildDeletedPost(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md":  "Date: 2016-05-12\n\n# First post\n\nThe first content",
+		"second.md": "Date: 2016-05-13\nTags: old\n\n# Second post\n\nThe second content",
+	})
+	defer ts.Close()
+	assert.Contains(ts.readOutput("index.html"), "Second post")
+
+	require.NoError(t, os.Remove(ts.path("content", "second.md")))
+	assert.NoError(ts.Rebuild())
+
+	assert.Len(ts.Context.Articles, 1)
+	assert.Empty(ts.Context.Tags)
+	assert.NotContains(ts.readOutput("index.html"), "Second post")
+	assert.NotContains(ts.readOutput("feeds/all.atom.xml"), "Second post")
+}
+
+func TestRebuildKeepsTheLastContentOnError(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md": "Date: 2016-05-12\n\n# First post\n\nThe first content",
+	})
+	defer ts.Close()
+
+	ts.writePost("second.md", "Date: tomorrow\n\n# Second post\n\nThe second content")
+	assert.Error(ts.Rebuild())
+
+	assert.Len(ts.Context.Articles, 1)
+	assert.Contains(ts.readOutput("index.html"), "First post")
+}
+
+func TestIncrementalBuild(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md":  "Date: 2016-05-12\nTags: go\n\n# First post\n\nThe first content",
+		"second.md": "Date: 2016-05-13\n\n# Second post\n\nThe second content",
+	})
+	defer ts.Close()
+	ts.Config.ShowTags = true
+	require.NoError(t, ts.Write())
+
+	// Outputs not written again keep the mark
+	ts.writeFile(filepath.Join("output", "first-post.html"), "mark")
+	ts.writeFile(filepath.Join("output", "tag", "go.html"), "mark")
+	assert.NoError(ts.Rebuild())
+	assert.Equal("mark", ts.readOutput("first-post.html"))
+
+	// The articles can list the other ones, but the tag only lists its own
+	ts.writePost("second.md", "Date: 2016-05-13\n\n# Second post\n\nThe edited content")
+	assert.NoError(ts.Rebuild())
+
+	assert.Equal("mark", ts.readOutput("tag/go.html"))
+	assert.Contains(ts.readOutput("first-post.html"), "The first content")
+	assert.Contains(ts.readOutput("second-post.html"), "The edited content")
+	assert.Contains(ts.readOutput("feeds/all.atom.xml"), "The edited content")
+
+	// The changes on the config affect all the outputs
+	ts.writeFile("config.json", `{"title": "New title", "paginationSize": 10}`)
+	site, err := New(ts.path("content"), ts.path("output"), ts.path("config.json"), ".", Options{})
+	require.NoError(t, err)
+	ts.Site = site
+	assert.NoError(ts.Write())
+	assert.Contains(ts.readOutput("first-post.html"), "New title")
+
+	ts.writeFile(filepath.Join("output", "first-post.html"), "mark")
+	ts.options.Force = true
+	assert.NoError(ts.Write())
+	assert.Contains(ts.readOutput("first-post.html"), "The first content")
+}
+
+func TestPruneStaleOutputs(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md": "Date: 2016-05-12\nTags: old\n\n# First post\n\nThe first content",
+	})
+	defer ts.Close()
+	ts.Config.Prune = true
+	ts.Config.ShowTags = true
+	ts.writeFile(filepath.Join("output", "CNAME"), "example.com")
+
+	ts.writePost("first.md", "Date: 2016-05-12\nSlug: renamed\nTags: new\n\n# First post\n\nThe first content")
+	assert.NoError(ts.Rebuild())
+
+	for _, name := range []string{"renamed.html", "tag/new.html", "index.html", "CNAME", manifestPath} {
+		_, err := os.Stat(ts.path("output", name))
+		assert.NoError(err, name)
+	}
+	for _, name := range []string{"first-post.html", "tag/old.html"} {
+		_, err := os.Stat(ts.path("output", name))
+		assert.True(os.IsNotExist(err), name)
+	}
+}
+
+func TestWriteClean(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md": "Date: 2016-05-12\n\n# First post\n\nThe first content",
+	})
+	defer ts.Close()
+	ts.writeFile(filepath.Join("output", "CNAME"), "example.com")
+	ts.writeFile(filepath.Join("output", "stale.html"), "stale")
+	ts.writeFile(filepath.Join("output", "first-post.html"), "mark")
+
+	assert.NoError(ts.WriteClean())
+	assert.Equal("example.com", ts.readOutput("CNAME"))
+	assert.Contains(ts.readOutput("first-post.html"), "The first content")
+	_, err := os.Stat(ts.path("output", "stale.html"))
+	assert.True(os.IsNotExist(err))
+}
+
+func TestFailedWriteCleanKeepsTheOutput(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md": "Date: 2016-05-12\n\n# First post\n\nThe first content",
+	})
+	defer ts.Close()
+
+	templatesPath := ts.path("custom", TemplatesRelativePath, "body", "content")
+	ts.copyTemplates(templatesPath)
+	ts.writeFile(filepath.Join("custom", TemplatesRelativePath, "body", "content", "article", "article.tmpl"),
+		`{{define "content"}}{{template "missing"}}{{end}}`)
+	ResetTemplates()
+	defer ResetTemplates()
+	ts.templatesBasePath = ts.path("custom")
+
+	assert.Error(ts.WriteClean())
+	assert.Contains(ts.readOutput("first-post.html"), "The first content")
+	assert.Contains(ts.readOutput("index.html"), "First post")
+}
+
+func TestDraftsArentPublishedAfterPreview(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md":  "Date: 2016-05-12\n\n# First post\n\nThe first content",
+		"secret.md": "Date: 2016-05-13\nStatus: draft\nTags: secret\n\n# Secret post\n\nThe secret content",
+	})
+	defer ts.Close()
+	ts.Config.ShowTags = true
+
+	ts.options.Drafts = true
+	assert.NoError(ts.Rebuild())
+	assert.Contains(ts.readOutput("secret-post.html"), "The secret content")
+	assert.Contains(ts.readOutput("tag/secret.html"), "Secret post")
+
+	// The drafts renamed or deleted while previewing
+	ts.writePost("secret.md", "Date: 2016-05-13\nStatus: draft\nSlug: secret-v2\nTags: secret\n\n# Secret post\n\nThe secret content")
+	ts.writePost("other.md", "Date: 2016-05-14\nStatus: draft\n\n# Other secret\n\nThe other content")
+	assert.NoError(ts.Rebuild())
+	assert.Contains(ts.readOutput("secret-v2.html"), "The secret content")
+	require.NoError(t, os.Remove(ts.path("content", "other.md")))
+	assert.NoError(ts.Rebuild())
+
+	// Without prune too
+	ts.options.Drafts = false
+	assert.NoError(ts.Rebuild())
+	for _, name := range []string{"secret-post.html", "secret-v2.html", "other-secret.html", "tag/secret.html", "tag"} {
+		_, err := os.Stat(ts.path("output", name))
+		assert.True(os.IsNotExist(err), name)
+	}
+	assert.NotContains(ts.readOutput("index.html"), "Secret post")
+	assert.Contains(ts.readOutput("first-post.html"), "The first content")
+}
+
+func TestFailedWriteKeepsTheOutput(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md": "Date: 2016-05-12\n\n# First post\n\nThe first content",
+	})
+	defer ts.Close()
+
+	// Templates that fail when rendering the articles
+	templatesPath := ts.path("custom", TemplatesRelativePath, "body", "content")
+	ts.copyTemplates(templatesPath)
+	ts.writeFile(filepath.Join("custom", TemplatesRelativePath, "body", "content", "article", "article.tmpl"),
+		`{{define "content"}}{{template "missing"}}{{end}}`)
+	ResetTemplates()
+	defer ResetTemplates()
+	ts.templatesBasePath = ts.path("custom")
+
+	ts.writePost("second.md", "Date: 2016-05-13\n\n# Second post\n\nThe second content")
+	assert.Error(ts.Rebuild())
+
+	assert.NotContains(ts.readOutput("index.html"), "Second post")
+	_, err := os.Stat(ts.path("output", "second-post.html"))
+	assert.True(os.IsNotExist(err))
+
+	// The staging folder is removed
+	names, err := readDirNames(ts.root)
+	assert.NoError(err)
+	sort.Strings(names)
+	assert.Equal([]string{"config.json", "content", "custom", "output"}, names)
+}
+
+func TestSwapOutputSymlink(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md": "Date: 2016-05-12\n\n# First post\n\nThe first content",
+	})
+	defer ts.Close()
+
+	// The output is a symlink to the last build, ex: to deploy it
+	require.NoError(t, os.Rename(ts.path("output"), ts.path("build")))
+	require.NoError(t, os.Symlink("build", ts.path("output")))
+
+	ts.writePost("second.md", "Date: 2016-05-13\n\n# Second post\n\nThe second content")
+	assert.NoError(ts.Rebuild())
+
+	info, err := os.Lstat(ts.path("output"))
+	require.NoError(t, err)
+	assert.True(info.Mode()&os.ModeSymlink != 0)
+	link, err := os.Readlink(ts.path("output"))
+	assert.NoError(err)
+	assert.False(filepath.IsAbs(link))
+	assert.Contains(ts.readOutput("index.html"), "Second post")
+	assert.Contains(ts.readOutput("first-post.html"), "The first content")
+
+	// The folder of the last build is removed
+	_, err = os.Stat(ts.path("build"))
+	assert.True(os.IsNotExist(err))
+}
+
+func TestSwapEntries(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, nil)
+	defer ts.Close()
+
+	// The staging folder is inside the outputs that are mount points
+	output := ts.path("output")
+	staging := filepath.Join(output, stagingPrefix+"output-1")
+	require.NoError(t, os.MkdirAll(filepath.Join(staging, "tag"), 0755))
+	ts.writeFile(filepath.Join("output", "stale.html"), "stale")
+	ts.writeFile(filepath.Join("output", "index.html"), "old")
+	ts.writeFile(filepath.Join("output", stagingPrefix+"output-1", "index.html"), "new")
+	ts.writeFile(filepath.Join("output", stagingPrefix+"output-1", "tag", "go.html"), "go")
+
+	assert.NoError(swapEntries(output, staging))
+	assert.Equal("new", ts.readOutput("index.html"))
+	assert.Equal("go", ts.readOutput("tag/go.html"))
+	names, err := readDirNames(output)
+	assert.NoError(err)
+	sort.Strings(names)
+	assert.Equal([]string{"index.html", "tag"}, names)
+}
+
+func TestWriteReportsAllTheErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md":  "Date: 2016-05-12\n\n# First post\n\nThe first content",
+		"second.md": "Date: 2016-05-13\n\n# Second post\n\nThe second content",
+	})
+	defer ts.Close()
+
+	templatesPath := ts.path("custom", TemplatesRelativePath, "body", "content")
+	ts.copyTemplates(templatesPath)
+	ts.writeFile(filepath.Join("custom", TemplatesRelativePath, "body", "content", "article", "article.tmpl"),
+		`{{define "content"}}{{.Missing}}{{end}}`)
+	ResetTemplates()
+	defer ResetTemplates()
+	ts.templatesBasePath = ts.path("custom")
+	ts.options.Force = true
+
+	err := ts.Write()
+	require.IsType(t, BuildErrors{}, err)
+	errs := err.(BuildErrors)
+	require.Len(t, errs, 2)
+
+	assert.Equal("first-post.html", errs[0].Output)
+	assert.Equal(ts.path("content", "first.md"), errs[0].Source)
+	assert.Equal("article", errs[0].Template)
+	assert.Equal("second-post.html", errs[1].Output)
+	assert.Equal(ts.path("content", "second.md"), errs[1].Source)
+	assert.Contains(err.Error(), "2 errors:")
+
+	b, err := json.Marshal(errs[0])
+	assert.NoError(err)
+	assert.Contains(string(b), `"output":"first-post.html"`)
+	assert.Contains(string(b), `"template":"article"`)
+	assert.Contains(string(b), `"error":`)
+}
+
+func TestLoadReportsAllTheErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, nil)
+	defer ts.Close()
+
+	ts.writePost("first.md", "Date: yesterday\n\n# First post\n\nThe first content")
+	ts.writePost("second.md", "Date: tomorrow\n\n# Second post\n\nThe second content")
+
+	err := ts.Rebuild()
+	require.IsType(t, BuildErrors{}, err)
+	errs := err.(BuildErrors)
+	require.Len(t, errs, 2)
+	assert.Equal(ts.path("content", "first.md"), errs[0].Source)
+	assert.Equal(ts.path("content", "second.md"), errs[1].Source)
+}
+
+func TestSchedulerLimitsTheWorkers(t *testing.T) {
+	assert := assert.New(t)
+
+	var mux sync.Mutex
+	running, maxRunning := 0, 0
+
+	sc := newScheduler(2)
+	for i := 0; i < 20; i++ {
+		i := i
+		sc.add(fmt.Sprintf("%d.html", i), func() (bool, error) {
+			mux.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mux.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mux.Lock()
+			running--
+			mux.Unlock()
+
+			if i%2 == 0 {
+				return false, &BuildError{Output: fmt.Sprintf("%d.html", i), Err: errors.New("failed")}
+			}
+			return true, nil
+		})
+	}
+
+	errs := sc.wait()
+	assert.Equal(2, maxRunning)
+	require.Len(t, errs, 10)
+	// In the order they were added, whatever order they finished in
+	for i, err := range errs {
+		assert.Equal(fmt.Sprintf("%d.html", i*2), err.Output)
+	}
+}
+
+func TestStats(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTestSite(t, map[string]string{
+		"first.md":  "Date: 2016-05-12\nTags: go\n\n# First post\n\nThe first content",
+		"second.md": "Date: 2016-05-13\nStatus: draft\n\n# Second post\n\nThe second content",
+	})
+	defer ts.Close()
+
+	st := ts.Stats()
+	assert.Equal(1, st.Articles)
+	assert.Equal(1, st.Drafts)
+	assert.Equal(1, st.Tags)
+	assert.Equal(0, st.UpToDate)
+	assert.Equal(len(st.Outputs), st.Written)
+	assert.True(st.Bytes > 0)
+
+	outputs := map[string]OutputStats{}
+	for _, output := range st.Outputs {
+		outputs[output.Output] = output
+	}
+	article := outputs["first-post.html"]
+	assert.Equal(ts.path("content", "first.md"), article.Source)
+	assert.Equal(articleTemplate, article.Template)
+	assert.Equal(int64(len(ts.readOutput("first-post.html"))), article.Bytes)
+
+	slowest := st.Slowest(2)
+	require.Len(t, slowest, 2)
+	assert.True(slowest[0].Duration >= slowest[1].Duration)
+
+	// Nothing changed, so nothing is written again
+	require.NoError(t, ts.Write())
+	st = ts.Stats()
+	assert.Equal(0, st.Written)
+	assert.Equal(len(outputs), st.UpToDate)
+	assert.Empty(st.SlowestTemplates(10))
+}
@@ -0,0 +1,412 @@
+package site
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// isIgnored returns true for the paths of the output that polo must never
+// remove, ex: CNAME. The patterns are matched against the path relative to the
+// output and against its name.
+func (s Site) isIgnored(relativePath string) bool {
+	if relativePath == manifestPath || strings.HasPrefix(filepath.Base(relativePath), stagingPrefix) {
+		return true
+	}
+	for _, pattern := range s.Config.PruneIgnore {
+		if ok, _ := filepath.Match(pattern, filepath.ToSlash(relativePath)); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(relativePath)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteClean writes the whole site again on an empty folder, only the ignored
+// paths of the output are kept. The output is replaced only if the build works,
+// as with Write.
+func (s Site) WriteClean() error {
+	s.clean = true
+	return s.Write()
+}
+
+// prune removes the files of the output that were not written by the last
+// build, and the folders that become empty.
+func (s Site) prune(written map[string]string) error {
+	var dirs []string
+	err := filepath.Walk(s.output, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(s.output, p)
+		if err != nil {
+			return err
+		}
+		if relativePath == "." {
+			return nil
+		}
+
+		if s.isIgnored(relativePath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			dirs = append(dirs, p)
+			return nil
+		}
+
+		if _, ok := written[filepath.ToSlash(relativePath)]; !ok {
+			log.Debug("Removing stale output: ", relativePath)
+			return os.Remove(p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The deepest folders first, so their parents can be empty after that
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, dir := range dirs {
+		if names, err := readDirNames(dir); err == nil && len(names) == 0 {
+			if err := os.Remove(dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removeOutputs removes the files of the output, and the folders that become
+// empty.
+func (s Site) removeOutputs(relativePaths []string) error {
+	for _, relativePath := range relativePaths {
+		log.Debug("Removing stale output: ", relativePath)
+		p := filepath.Join(s.output, filepath.FromSlash(relativePath))
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		for dir := filepath.Dir(p); dir != s.output && strings.HasPrefix(dir, s.output); dir = filepath.Dir(dir) {
+			if names, err := readDirNames(dir); err != nil || len(names) > 0 {
+				break
+			}
+			if err := os.Remove(dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readDirNames(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdirnames(-1)
+}
+
+// stagingPrefix starts the names of the staging folders.
+const stagingPrefix = ".polo-staging-"
+
+// stage creates a folder with the same files as the output. The site is
+// written there and swapped with the output only if there are no errors, so
+// nobody sees a half written site.
+func (s Site) stage() (string, error) {
+	output, err := filepath.Abs(s.output)
+	if err != nil {
+		return "", err
+	}
+	parent, err := stagingParent(output)
+	if err != nil {
+		return "", err
+	}
+
+	staging, err := ioutil.TempDir(parent, stagingPrefix+filepath.Base(output)+"-")
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(output); err == nil {
+		if err := os.Chmod(staging, info.Mode().Perm()); err != nil {
+			os.RemoveAll(staging)
+			return "", err
+		}
+	}
+
+	// The files not written again by the build are kept from the last one
+	link := linkTree
+	if s.clean {
+		link = s.linkIgnored
+	}
+	if err := link(output, staging); err != nil && !os.IsNotExist(err) {
+		os.RemoveAll(staging)
+		return "", err
+	}
+	return staging, nil
+}
+
+// linkIgnored links only the ignored entries of src on dst, the manifest
+// excluded so all the outputs are written again.
+func (s Site) linkIgnored(src, dst string) error {
+	names, err := readDirNames(src)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name == manifestPath || strings.HasPrefix(name, stagingPrefix) || !s.isIgnored(name) {
+			continue
+		}
+
+		p := filepath.Join(src, name)
+		info, err := os.Lstat(p)
+		if err != nil {
+			return err
+		}
+		if err := linkEntry(p, filepath.Join(dst, name), info); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if err := linkTree(p, filepath.Join(dst, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stagingParent returns where the staging folder is created, it must be on the
+// same file system as the output to link its files and rename it. That's the
+// folder with the output, the folder with the target of the output if it's a
+// symlink, or the output itself if it's a mount point.
+func stagingParent(output string) (string, error) {
+	target, err := filepath.EvalSymlinks(output)
+	if os.IsNotExist(err) {
+		return filepath.Dir(output), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if isMountPoint(target) {
+		return target, nil
+	}
+	return filepath.Dir(target), nil
+}
+
+// swap replaces the output with the staging folder. On Linux both folders are
+// exchanged atomically, so the output always exists. The other systems rename
+// the output away before renaming the staging folder, and the output doesn't
+// exist for a moment.
+func swap(output, staging string) error {
+	output, err := filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Lstat(output)
+	switch {
+	case os.IsNotExist(err):
+		return os.Rename(staging, output)
+	case err != nil:
+		return err
+	case info.Mode()&os.ModeSymlink != 0:
+		return swapSymlink(output, staging)
+	case isMountPoint(output):
+		return swapEntries(output, staging)
+	}
+
+	if err := exchange(staging, output); err == nil {
+		// The staging folder has the last build now
+		return os.RemoveAll(staging)
+	}
+
+	old := staging + "-old"
+	if err := os.Rename(output, old); err != nil {
+		return err
+	}
+	if err := os.Rename(staging, output); err != nil {
+		// Restore the last build
+		os.Rename(old, output)
+		return err
+	}
+	return os.RemoveAll(old)
+}
+
+// swapSymlink points the output symlink to the staging folder. The symlink is
+// replaced atomically, and the folder of the last build is removed after that.
+func swapSymlink(output, staging string) error {
+	link, err := os.Readlink(output)
+	if err != nil {
+		return err
+	}
+	target, err := filepath.EvalSymlinks(output)
+	if err != nil {
+		return err
+	}
+
+	// Keep the symlink relative if it was
+	newLink := staging
+	if !filepath.IsAbs(link) {
+		if newLink, err = filepath.Rel(filepath.Dir(output), staging); err != nil {
+			return err
+		}
+	}
+
+	tmp := filepath.Join(filepath.Dir(output), stagingPrefix+filepath.Base(output)+"-link")
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Symlink(newLink, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, output); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.RemoveAll(target)
+}
+
+// swapEntries moves the content of the staging folder into the output, for the
+// outputs that can't be renamed, ex: mount points. Every entry is replaced
+// atomically on Linux, but not the whole output.
+func swapEntries(output, staging string) error {
+	names, err := readDirNames(staging)
+	if err != nil {
+		return err
+	}
+	staged := make(map[string]bool, len(names))
+	for _, name := range names {
+		staged[name] = true
+
+		src, dst := filepath.Join(staging, name), filepath.Join(output, name)
+		if err := exchange(src, dst); err == nil {
+			continue
+		}
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		if err := os.Rename(src, dst); err != nil {
+			return err
+		}
+	}
+
+	// The entries removed by the build
+	names, err = readDirNames(output)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if staged[name] || strings.HasPrefix(name, stagingPrefix) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(output, name)); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(staging)
+}
+
+// linkTree recreates the folders of src on dst with hard links to its files.
+// The files are copied if they can't be linked. The staging folders, that can
+// be inside src, are skipped.
+func linkTree(src, dst string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(src, p)
+		if err != nil || relativePath == "." {
+			return err
+		}
+		if info.IsDir() && strings.HasPrefix(info.Name(), stagingPrefix) {
+			return filepath.SkipDir
+		}
+		return linkEntry(p, filepath.Join(dst, relativePath), info)
+	})
+}
+
+// linkEntry recreates a folder or a symlink, or links a file.
+func linkEntry(p, target string, info os.FileInfo) error {
+	switch {
+	case info.IsDir():
+		return os.Mkdir(target, info.Mode().Perm())
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(p)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, target)
+	}
+
+	if err := os.Link(p, target); err == nil {
+		return nil
+	}
+	return copyFile(p, target, info.Mode().Perm())
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return syncAndClose(out)
+}
+
+// writeFile writes the content on a file of the output, creating its folder if
+// needed.
+func (s Site) writeFile(relativePath string, content []byte) error {
+	if err := s.mkdirP(relativePath); err != nil {
+		return err
+	}
+
+	f, err := s.create(relativePath)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	return syncAndClose(f)
+}
+
+// syncAndClose flushes the file to the disk before closing it, so the swapped
+// output is complete even if the machine crashes right after the build.
+func syncAndClose(f *os.File) error {
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// create creates a file on the output. The file is removed before because it
+// could be a hard link to the same file of the last build.
+func (s Site) create(relativePath string) (*os.File, error) {
+	p := path.Join(s.output, relativePath)
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return os.Create(p)
+}
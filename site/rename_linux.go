@@ -0,0 +1,34 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// exchange swaps two paths atomically, so both of them exist all the time.
+func exchange(oldpath, newpath string) error {
+	err := unix.Renameat2(unix.AT_FDCWD, oldpath, unix.AT_FDCWD, newpath, unix.RENAME_EXCHANGE)
+	if err != nil {
+		return &os.LinkError{Op: "exchange", Old: oldpath, New: newpath, Err: err}
+	}
+	return nil
+}
+
+// isMountPoint returns true if the folder is on a different device than its
+// parent.
+func isMountPoint(p string) bool {
+	info, err := os.Stat(p)
+	if err != nil {
+		return false
+	}
+	parent, err := os.Stat(filepath.Dir(p))
+	if err != nil {
+		return false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	parentStat, parentOk := parent.Sys().(*syscall.Stat_t)
+	return ok && parentOk && stat.Dev != parentStat.Dev
+}
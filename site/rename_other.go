@@ -0,0 +1,22 @@
+//go:build !linux
+// +build !linux
+
+package site
+
+import (
+	"errors"
+	"os"
+)
+
+var errExchangeUnsupported = errors.New("not supported on this system")
+
+// exchange is only atomic on Linux, the callers fall back to two renames.
+func exchange(oldpath, newpath string) error {
+	return &os.LinkError{Op: "exchange", Old: oldpath, New: newpath, Err: errExchangeUnsupported}
+}
+
+// isMountPoint can't be known without the Linux syscalls, the outputs are
+// assumed to be regular folders.
+func isMountPoint(p string) bool {
+	return false
+}
@@ -0,0 +1,81 @@
+package site
+
+import (
+	"runtime"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// job writes one output of the site. It returns false if the output was
+// already up to date.
+type job struct {
+	output string
+	run    func() (bool, error)
+}
+
+// result is the outcome of a job.
+type result struct {
+	written bool
+	err     error
+}
+
+// scheduler runs the jobs with a limited number of workers, so a big site
+// doesn't open thousands of files at the same time.
+type scheduler struct {
+	workers int
+	jobs    []job
+}
+
+// newScheduler returns a scheduler with the given number of workers, or one
+// per CPU if it isn't positive.
+func newScheduler(workers int) *scheduler {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &scheduler{workers: workers}
+}
+
+// add queues a job, they are only run by wait.
+func (sc *scheduler) add(output string, run func() (bool, error)) {
+	sc.jobs = append(sc.jobs, job{output: output, run: run})
+}
+
+// wait runs all the queued jobs and returns their errors. The outputs are
+// logged in the order the jobs were added, whatever order they finished in.
+func (sc *scheduler) wait() BuildErrors {
+	results := make([]result, len(sc.jobs))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < sc.workers && i < len(sc.jobs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				written, err := sc.jobs[i].run()
+				results[i] = result{written: written, err: err}
+			}
+		}()
+	}
+	for i := range sc.jobs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var errs BuildErrors
+	for i, result := range results {
+		output := sc.jobs[i].output
+		switch {
+		case result.err != nil:
+			errs = append(errs, toBuildError(result.err))
+		case result.written:
+			log.Debug("Written: ", output)
+		default:
+			log.Debug("Up to date: ", output)
+		}
+	}
+	sc.jobs = nil
+	return errs
+}
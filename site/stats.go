@@ -0,0 +1,168 @@
+package site
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/agonzalezro/polo/context"
+	"github.com/agonzalezro/polo/file"
+)
+
+// Stats are the numbers of the last build, to track where the time is spent.
+// The markdown, templates and disk durations are the sum of all the files, so
+// they can be longer than the build when the files are written in parallel.
+type Stats struct {
+	Articles, Pages, Drafts, Tags, Categories int
+
+	Written  int   // Outputs written by the last build
+	UpToDate int   // Outputs that didn't change since the build before it
+	Bytes    int64 // Of the outputs written
+
+	Load      time.Duration // Parsing the content, markdown included
+	Markdown  time.Duration
+	Templates time.Duration
+	Disk      time.Duration
+	Write     time.Duration // Writing the whole site
+
+	Outputs []OutputStats // Only the ones written
+}
+
+// OutputStats are the numbers of an output of the site.
+type OutputStats struct {
+	Output   string
+	Source   string // Empty for the outputs listing several sources
+	Template string
+	Bytes    int64
+	Duration time.Duration // Executing the template and writing it
+}
+
+// TemplateStats are the numbers of all the outputs of a template.
+type TemplateStats struct {
+	Template string
+	Outputs  int
+	Duration time.Duration
+}
+
+// Slowest returns the n outputs that took longer to write.
+func (st Stats) Slowest(n int) []OutputStats {
+	outputs := append([]OutputStats(nil), st.Outputs...)
+	sort.Slice(outputs, func(i, j int) bool {
+		if outputs[i].Duration != outputs[j].Duration {
+			return outputs[i].Duration > outputs[j].Duration
+		}
+		return outputs[i].Output < outputs[j].Output
+	})
+	if len(outputs) > n {
+		outputs = outputs[:n]
+	}
+	return outputs
+}
+
+// SlowestTemplates returns the n templates that took longer to write all
+// their outputs.
+func (st Stats) SlowestTemplates(n int) []TemplateStats {
+	byName := make(map[string]*TemplateStats)
+	var templates []TemplateStats
+	for _, output := range st.Outputs {
+		if output.Template == "" {
+			continue
+		}
+		if _, ok := byName[output.Template]; !ok {
+			byName[output.Template] = &TemplateStats{Template: output.Template}
+		}
+		byName[output.Template].Outputs++
+		byName[output.Template].Duration += output.Duration
+	}
+	for _, template := range byName {
+		templates = append(templates, *template)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Duration != templates[j].Duration {
+			return templates[i].Duration > templates[j].Duration
+		}
+		return templates[i].Template < templates[j].Template
+	})
+	if len(templates) > n {
+		templates = templates[:n]
+	}
+	return templates
+}
+
+// buildStats collects the stats from all the writers.
+type buildStats struct {
+	mux   sync.Mutex
+	stats Stats
+
+	// Of the content being loaded, they are kept if the load fails
+	drafts   int
+	markdown time.Duration
+}
+
+func (b *buildStats) startLoad() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.drafts, b.markdown = 0, 0
+}
+
+func (b *buildStats) addSource(f file.ParsedFile) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if f.IsDraft {
+		b.drafts++
+	}
+	b.markdown += f.RenderTime()
+}
+
+func (b *buildStats) endLoad(c *context.Context, d time.Duration) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.stats.Articles = len(c.Articles)
+	b.stats.Pages = len(c.Pages)
+	b.stats.Tags = len(c.Tags)
+	b.stats.Categories = len(c.Categories)
+	b.stats.Drafts = b.drafts
+	b.stats.Markdown = b.markdown
+	b.stats.Load = d
+}
+
+func (b *buildStats) startWrite() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.stats.Written, b.stats.UpToDate, b.stats.Bytes = 0, 0, 0
+	b.stats.Templates, b.stats.Disk, b.stats.Write = 0, 0, 0
+	b.stats.Outputs = nil
+}
+
+func (b *buildStats) addUpToDate() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.stats.UpToDate++
+}
+
+func (b *buildStats) addOutput(output OutputStats, templates, disk time.Duration) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	output.Duration = templates + disk
+	b.stats.Outputs = append(b.stats.Outputs, output)
+	b.stats.Written++
+	b.stats.Bytes += output.Bytes
+	b.stats.Templates += templates
+	b.stats.Disk += disk
+}
+
+func (b *buildStats) endWrite(d time.Duration) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.stats.Write = d
+}
+
+// Stats returns the numbers of the last build.
+func (s *Site) Stats() Stats {
+	s.stats.mux.Lock()
+	defer s.stats.mux.Unlock()
+	st := s.stats.stats
+	st.Outputs = append([]OutputStats(nil), st.Outputs...)
+	return st
+}
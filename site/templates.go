@@ -1,9 +1,15 @@
 package site
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path"
+	"sort"
+	"sync"
 	"text/template"
 
 	log "github.com/Sirupsen/logrus"
@@ -13,12 +19,31 @@ import (
 // TODO: probably to be override from the cmd
 const TemplatesRelativePath = "templates"
 
-var templates map[string]*template.Template
+var (
+	templates    map[string]*template.Template
+	templatesSum string // The hash of the sources of the templates
+	templatesMux = &sync.Mutex{}
+)
+
+// ResetTemplates forgets the parsed templates, they will be parsed again on
+// the next write. It's needed to reload the templates modified on disk.
+func ResetTemplates() {
+	templatesMux.Lock()
+	defer templatesMux.Unlock()
+	templates = nil
+	templatesSum = ""
+}
 
 // TODO: this could be our own type based on template.Template
-func parseFileOrAsset(t *template.Template, p string) (*template.Template, error) {
+// The source of the template is written to h to know when it changes.
+func parseFileOrAsset(t *template.Template, p string, h io.Writer) (*template.Template, error) {
 	if _, err := os.Stat(p); err == nil {
 		log.Debug("Loading template from disk: ", p)
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(b)
 		return t.ParseFiles(p)
 	}
 
@@ -27,12 +52,13 @@ func parseFileOrAsset(t *template.Template, p string) (*template.Template, error
 		return nil, err
 	}
 	log.Debug("Loading template from asset: ", p)
+	h.Write(b)
 	return t.Parse(string(b))
 }
 
-// mustParseCommonTemplates will parse common templates and fatal if it can.
-// Common templates are those templates shared.
-func mustParseCommonTemplates() *template.Template {
+// parseCommonTemplates will parse common templates. Common templates are those
+// templates shared.
+func parseCommonTemplates(h io.Writer) (*template.Template, error) {
 	// We can't just walk a dir to find them because GOPATH will not be available
 	// on a binary installation.
 	// TODO: it would be cool to use go-bindata list but _bindata is private.
@@ -50,15 +76,15 @@ func mustParseCommonTemplates() *template.Template {
 	tpl := template.New("common")
 	var err error
 	for _, p := range commonTemplatePaths {
-		tpl, err = parseFileOrAsset(tpl, p)
+		tpl, err = parseFileOrAsset(tpl, p, h)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 	}
-	return tpl
+	return tpl, nil
 }
 
-func mustParseContentTemplates(commonTpl *template.Template, templatesPath string) map[string]*template.Template {
+func parseContentTemplates(commonTpl *template.Template, templatesPath string, h io.Writer) (map[string]*template.Template, error) {
 	// Content templates are those templates that are willing to change depending
 	// on what we are rending at that moment.
 	contentTemplatePaths := make(map[string][]string)
@@ -73,51 +99,75 @@ func mustParseContentTemplates(commonTpl *template.Template, templatesPath strin
 		contentTemplatePaths[templateName] = paths
 	}
 
-	templates = make(map[string]*template.Template) // WARNING package level var
-	for name, paths := range contentTemplatePaths {
+	// Sorted, to always hash the sources in the same order
+	var names []string
+	for name := range contentTemplatePaths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parsed := make(map[string]*template.Template)
+	for _, name := range names {
+		paths := contentTemplatePaths[name]
 		tpl, err := commonTpl.Clone()
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 
 		for _, p := range paths {
 			p = path.Join(templatesPath, "body", "content", p)
-			tpl, err = parseFileOrAsset(tpl, p)
+			tpl, err = parseFileOrAsset(tpl, p, h)
 			if err != nil {
-				log.Fatal(err)
+				return nil, err
 			}
 		}
-		templates[name] = tpl
+		parsed[name] = tpl
 	}
 
 	// Atom template doesn't inherit from any shared template
 	tpl := template.New("atom")
-	tpl, err := parseFileOrAsset(tpl, "templates/atom.tmpl")
+	tpl, err := parseFileOrAsset(tpl, "templates/atom.tmpl", h)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	templates[atomTemplate] = tpl
+	parsed[atomTemplate] = tpl
 
-	return templates
+	return parsed, nil
 }
 
-func (s *Site) getTemplates() map[string]*template.Template {
-	s.mux.Lock()
-	defer s.mux.Unlock()
+// getTemplates parses the templates only the first time, they are kept on a
+// package level var after that.
+func (s *Site) getTemplates() (map[string]*template.Template, error) {
+	templatesMux.Lock()
+	defer templatesMux.Unlock()
 
 	if templates != nil {
-		return templates
+		return templates, nil
 	}
 
 	templatesPath := path.Join(s.templatesBasePath, TemplatesRelativePath)
 	log.Debug("Templates path: ", templatesPath)
 
-	commonTpl := mustParseCommonTemplates()
-	return mustParseContentTemplates(commonTpl, templatesPath)
+	h := sha1.New()
+	commonTpl, err := parseCommonTemplates(h)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parseContentTemplates(commonTpl, templatesPath, h)
+	if err != nil {
+		return nil, err
+	}
+	templates = parsed
+	templatesSum = hex.EncodeToString(h.Sum(nil))
+	return templates, nil
 }
 
 func (s *Site) getTemplate(name string) (*template.Template, error) {
-	if v, ok := s.getTemplates()[name]; ok {
+	templates, err := s.getTemplates()
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := templates[name]; ok {
 		return v, nil
 	}
 	return nil, fmt.Errorf("Template '%s' not found!", name)
@@ -84,7 +84,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _templatesAtomTmpl = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x6c\x92\x51\x4f\xc3\x20\x14\x85\xdf\xf9\x15\x37\x64\x8f\x5a\xa6\xc6\xc4\x2c\xb4\xcb\xa2\x8f\x3e\xa9\x7b\x36\xb8\xde\x76\xc4\x16\x16\x4a\xed\x0c\xe1\xbf\x1b\xe8\x58\xb7\xc6\x37\xe0\x3b\xf7\x9e\x73\x9a\x3a\x57\x62\x25\x15\x02\xfd\x12\x1d\x52\xef\x09\x5f\x1f\xdb\x06\x7e\xd0\x74\x52\xab\x9c\xde\x65\x4b\x0a\xa8\x76\xba\x94\xaa\xce\x69\x6f\xab\xdb\x27\xba\x2e\x08\xe1\x15\x62\x09\xc7\xb6\x51\x5d\x4e\xf7\xd6\x1e\x56\x8c\x0d\xc3\x90\x0d\x0f\x99\x36\x35\xbb\x5f\x2e\x1f\xd9\xc6\xea\x96\x16\x04\x80\x5b\x69\x1b\x2c\x9c\xcb\x9e\xb5\xaa\x64\x9d\x7d\x84\xbb\xf7\x9c\x8d\x20\x48\x1a\xa9\xbe\x61\x6f\xb0\xca\xe9\xa4\xdb\xbe\xbd\x7a\x4f\x81\x45\x45\x7f\x28\x85\xc5\x32\xac\xd9\x8e\xc7\xb0\x21\xbd\x12\x02\xe0\x9c\xac\x20\xdb\x18\x2b\x77\x0d\x76\xde\xc7\x27\x23\x54\x8d\xb0\xf8\xbc\x81\x85\x18\x09\xac\xf2\x99\x8a\xa3\xb2\xe6\x37\xb8\x5c\x84\x4d\xf2\x7f\xd2\xce\xf3\x2e\xae\x02\x5f\x8c\xbe\x37\x7d\x7d\x6e\x70\x0a\x78\x86\x2f\xc2\x62\xb4\xbf\x2a\x37\xc3\x17\x0d\xc7\x0d\xa8\xca\x34\xd4\xf5\x6d\x2b\x52\xee\xc0\x26\xdf\x91\x24\x21\xbb\x52\x72\xd1\xdb\xbd\x36\x69\x8c\x2b\xd1\xc6\xbe\xa9\xc4\x26\xe2\x60\x1d\xc9\x69\xc5\x34\xc4\xd9\xf9\x73\x4d\x71\xd2\x89\xb3\xf0\x67\x14\x24\xdd\xff\x02\x00\x00\xff\xff\x3c\xc3\x3c\x5e\x63\x02\x00\x00")
+var _templatesAtomTmpl = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x02\xff\x6c\x52\x3d\xaf\x9b\x30\x14\xdd\xf9\x15\x57\x56\xc6\x06\x3b\x69\xd3\x56\xc8\xf0\x14\xb5\xea\xf4\xba\x34\xc9\xd2\xa5\x72\xe3\x0b\xb1\x0a\x76\x64\x4c\x49\x85\xfc\xdf\x2b\x20\x26\x10\xbd\xcd\xf6\x39\xf7\x7c\x5c\xb9\xeb\x24\xe6\x4a\x23\x90\xdf\xa2\x46\xe2\x7d\xc4\x5f\x6e\x55\x09\x7f\xd1\xd6\xca\xe8\x94\x6c\x62\x46\x00\xf5\xd9\x48\xa5\x8b\x94\x34\x2e\x5f\x7f\x26\x2f\x59\x14\xf1\x1c\x51\xc2\xad\x2a\x75\x9d\x92\x8b\x73\xd7\x84\xd2\xb6\x6d\xe3\xf6\x7d\x6c\x6c\x41\xb7\x8c\xed\xe8\xde\x99\x8a\x64\x11\x00\x77\xca\x95\x98\x75\x5d\xfc\xc5\xe8\x5c\x15\xf1\xb1\xbf\x7b\xcf\xe9\x08\xf4\x94\x52\xe9\x3f\x70\xb1\x98\xa7\xe4\xc1\x3b\xfd\x78\xf5\x9e\x00\x1d\x18\xcd\x55\x0a\x87\xb2\x97\x39\x8d\xc7\x5e\x21\xbc\x46\x11\x40\xd7\xa9\x1c\xe2\xbd\x75\xea\x5c\x62\xed\xfd\xf0\x64\x85\x2e\x10\x56\xbf\xde\xc1\x4a\x8c\x08\x24\xe9\x13\x8b\xa3\x76\xf6\x5f\xef\x32\x0b\x1b\xe8\x6f\xa4\x7d\xce\xbb\x5a\x04\xa6\xb3\xd9\x43\xd9\x14\x53\x85\x7b\xc2\x09\xfc\x2a\x1c\x0e\xfe\x8b\x76\x13\xfc\x2a\x6a\xf7\xdd\x48\x95\x2b\x94\xf1\x37\x63\x2b\xe1\x80\x6c\x19\xfb\xb8\x66\x9b\x35\xdb\x1e\x37\xbb\x84\x7d\x48\xd8\xee\x27\xfb\x94\x30\x46\x16\xdb\x18\xcd\x50\xcb\xa0\x5f\x37\x55\x25\x42\x47\x80\x99\xcd\x61\x44\x02\x91\x2e\x98\x5c\x34\xee\x62\x6c\x18\xe3\x5a\x54\x98\xcd\x0a\xef\x07\xb8\xb7\x1e\x90\xbb\xc4\x63\x88\xd3\x69\xb5\x8f\x38\xe1\xc4\x69\xff\x8b\xb2\x28\xdc\xff\x0f\x00\x4d\x23\xfe\xda\x8f\x02\x00\x00")
 
 func templatesAtomTmplBytes() ([]byte, error) {
 	return bindataRead(
@@ -99,7 +99,7 @@ func templatesAtomTmpl() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "templates/atom.tmpl", size: 611, mode: os.FileMode(420), modTime: time.Unix(1481713853, 0)}
+	info := bindataFileInfo{name: "templates/atom.tmpl", size: 655, mode: os.FileMode(420), modTime: time.Unix(1481713853, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -164,7 +164,7 @@ func templatesBodyContentArchiveTmpl() (*asset, error) {
 	return a, nil
 }
 
-var _templatesBodyContentArticleArticleTmpl = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x8c\x93\xcf\x8e\xdb\x20\x10\xc6\xef\x7e\x8a\x11\xda\x63\x6d\x37\x3d\x55\x15\xb1\xb4\xda\x1e\xfa\x00\x7b\x5f\x11\x3c\xd8\xa8\x18\x22\x43\x56\xda\x4e\x79\xf7\x0a\x1c\x12\x27\xb1\xd4\xbd\x24\xcc\xbf\xef\xfb\x99\xb1\x89\x7a\x54\xda\x22\xb0\xa0\x83\x41\x16\x63\x45\xd4\x3c\xcf\x41\x4b\x83\xcd\x6b\xca\xc5\x08\x7f\x81\xa8\x79\x71\x56\xe9\xa1\xe4\x2a\x22\xb4\x7d\x8c\x55\x75\x95\x90\xce\x06\xb4\x21\x89\xf0\x5e\xbf\x83\x34\xc2\xfb\x7d\x4e\x0b\x6d\x71\x06\xe9\x4c\x3d\xf5\xf5\xee\x6b\x39\x39\xa5\x3c\x86\x7a\x97\x63\x33\xd4\xdf\xcb\xe1\x5c\xf8\xc6\xba\x0a\x80\x8f\xbb\xee\x91\x8a\xb7\xe3\xae\xab\x52\xf9\x98\x9a\x00\x88\xb4\x02\x37\x43\x21\x7d\x3e\x85\x31\x85\x65\x6e\x89\x63\xcc\xcd\xdc\x1f\x85\x2d\x84\x46\x1c\xd0\x40\xfe\xad\x7b\x54\xe2\x64\x02\xeb\x0e\x1f\x8b\xe2\xc3\xfc\x0a\xe5\x9a\x42\xe3\x31\x97\x6e\xcc\x73\x25\x5d\x13\x6f\x93\x5f\xc6\x85\x3b\xd9\x9f\x22\xe0\xa7\xa1\x88\x9a\x5f\xa7\x49\x58\xfd\x07\xd3\x5c\xd0\x13\xde\x2b\x15\xab\xc5\xe9\xbc\xa4\x47\xdb\x17\x11\x70\x70\xf3\x47\xb1\x16\x5b\xbe\xfe\x24\x25\x7a\xcf\x60\x9c\x51\xed\x59\x2b\xcf\x43\xed\xea\x0e\xae\x42\xcd\x18\x26\xc3\xba\xcd\x1a\x6f\xc5\x7f\x91\x5e\xc5\xe0\xcf\x38\x44\xb3\xb0\x03\xc2\xd3\xdb\x17\x78\x0a\x62\x80\x1f\xfb\xcd\xbe\x6d\x6c\x6d\x95\xbb\x30\x07\x31\xb4\x44\x49\x64\x45\xb8\x84\x0f\x50\xb7\x67\xde\x1e\xf3\xc6\xd6\x0f\xb4\xbc\xe2\x0b\x3f\xd1\xc1\x38\xf9\x1b\x98\x1f\xc5\x8c\x6f\x5a\x3a\xeb\x19\x34\x97\xa5\xaf\x5b\xa4\x9b\x26\xb4\xe1\xb6\xce\xdb\x5e\xbf\x77\x97\x4f\xa9\xfc\xff\x0b\x00\x00\xff\xff\xf2\xe3\x47\x5b\x95\x03\x00\x00")
+var _templatesBodyContentArticleArticleTmpl = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x02\xff\x9c\x94\x41\x6f\x9c\x30\x10\x85\xef\xfc\x8a\x91\x95\x63\x81\x6e\x4f\x55\x45\x90\x56\xc9\xa1\x3d\xf4\x96\x7b\xe4\xb5\xc7\x60\xd5\xd8\x2b\xdb\xa4\x4a\xa7\xfe\xef\x15\x2c\x26\xbb\x09\x4a\x56\xbd\x20\x7b\xde\x9b\x37\x9f\x11\x86\x48\xa2\xd2\x16\x81\x45\x1d\x0d\xb2\x94\x0a\xa2\x6a\xef\xa3\x16\x06\xab\x87\xa9\x96\x12\xfc\x05\xa2\xea\xce\x59\xa5\xbb\x5c\x2b\x88\xd0\xca\x94\x8a\xe2\x25\x42\x38\x1b\xd1\xc6\x29\xa4\x91\xfa\x09\x84\xe1\x21\xdc\xce\x65\xae\x2d\x7a\x10\xce\x94\x83\x2c\x77\x9f\xf3\xca\x29\x15\x30\x96\xbb\x79\x6f\xba\xf2\x6b\x5e\x2c\xc2\x17\xd6\x16\x00\x4d\xbf\x6b\xdf\x52\x35\x75\xbf\x6b\x8b\x49\x3e\x4e\x26\x00\x22\xad\x60\x75\xfd\x08\xf7\x9e\xab\x98\xd2\xac\x35\xe1\xc8\x6d\x06\x32\xfc\x80\x06\xe6\x67\xf9\x9b\x7b\xab\x6d\xc7\x5a\x39\xb9\x9b\x7a\xf2\xe5\xb8\xe5\x80\x6b\xb6\xf3\x90\xdf\xc2\x7e\x8c\xbd\xf3\x2f\xd3\x4e\xfb\x8f\x86\x49\x54\x7c\x34\x91\xb5\x87\xe7\x57\xb4\xb9\x9f\x68\xa3\x84\x26\xe0\x2c\x5d\x0c\x4f\x69\x21\x7c\x1f\x7a\xcd\xbb\xe7\x11\xaf\x06\x24\xaa\xbe\x8f\x03\xb7\xfa\x0f\x4e\x7d\x51\x0f\xf8\x3a\xe9\xca\xb1\x3f\x9d\xd4\x4a\xa3\xac\xf6\x2a\xa2\xff\x4f\x9c\xf1\x28\x79\x44\x09\xce\xc2\xbb\x64\x79\xd8\xd5\x74\x77\x3c\x62\xe7\xfc\x73\x26\xe1\x5b\x18\x61\x14\x02\x43\x60\xd0\x7b\x54\xb7\xac\x16\x4b\x53\x4d\xb4\x11\x54\xf5\x71\x30\xac\xdd\xd4\x9a\x9a\x7f\x88\xf4\xc0\xbb\xb0\xe0\x10\x79\x6e\x3b\x84\x9b\xc7\x4f\x70\x13\x79\x07\xdf\x6e\x37\x7d\xdb\xd8\xda\x2a\xb7\x32\x47\xde\xd5\x44\x53\xc8\x19\xe1\x69\xfb\x06\xea\x72\xdd\xd4\xc7\xf9\x9a\x9d\x1f\xe8\x74\xd1\x4f\xfc\x44\x07\xe3\xc4\x2f\x60\xa1\xe7\x1e\x1f\xb5\x70\x36\x30\xa8\xd6\xcf\xf3\xdc\x22\xdc\x30\xa0\x8d\x97\x7a\x53\x4b\xfd\xd4\xae\x3f\x94\x7f\x03\x00\x3d\xb4\x7b\x8d\x93\x04\x00\x00")
 
 func templatesBodyContentArticleArticleTmplBytes() ([]byte, error) {
 	return bindataRead(
@@ -179,7 +179,7 @@ func templatesBodyContentArticleArticleTmpl() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "templates/body/content/article/article.tmpl", size: 917, mode: os.FileMode(420), modTime: time.Unix(1481713853, 0)}
+	info := bindataFileInfo{name: "templates/body/content/article/article.tmpl", size: 1171, mode: os.FileMode(420), modTime: time.Unix(1481713853, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -244,7 +244,7 @@ func templatesBodyContentCategoryTmpl() (*asset, error) {
 	return a, nil
 }
 
-var _templatesBodyContentIndexTmpl = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xa4\x56\x41\x6f\xdb\x3c\x0c\xbd\xe7\x57\xa8\xfe\x82\xef\x50\x2c\x71\xb3\xd3\xb0\xb9\x06\x8a\xf6\xb0\xc3\xd0\x15\x68\xef\x05\x63\xd3\xb2\x30\x59\x4e\x25\x3a\x68\x67\xf8\xbf\x0f\x92\xe5\x44\x4a\x5d\xac\xc5\x2e\x81\x25\x92\x4f\x8f\x7c\x4f\x42\xfa\xbe\xc4\x4a\x28\x64\x09\x09\x92\x98\x0c\xc3\xa2\xef\xd7\xd7\xad\xaa\x04\x5f\x3f\xd8\x2d\xb7\x83\xaa\x1c\x86\xc5\xe2\x98\x5d\xb4\x8a\x50\x91\xcd\xcf\x4a\xb1\x67\x85\x04\x63\x2e\xdd\x36\x08\x85\x9a\x15\xad\x5c\x35\xe5\x6a\x73\x31\x7d\xb5\x55\x65\x90\x56\x1b\xb7\x96\x7c\xf5\x65\xfa\xf0\x81\xcf\x49\xbe\x60\xac\xef\x45\xc5\xd6\x57\x9a\x44\x21\xd1\x0c\xc3\x82\x31\xbb\xa9\x41\x71\x64\xcb\xc7\x4f\x6c\x09\x63\x8c\x7d\xbd\x7c\x95\x97\xf9\x58\xee\x56\x8c\x65\xf5\x26\xcf\x80\xd5\x1a\xab\xcb\xa4\xef\xa7\xd2\xf5\xbd\xec\xf8\x30\x24\x79\xb0\xe5\x5b\xcd\x52\xc8\xb3\xb4\xde\xe4\x8b\x09\x62\x37\x81\x79\x6e\xad\x66\xcb\x69\x3e\x57\x1d\xd5\x76\x3d\x81\x8c\x6b\x4f\xc6\x55\x9b\x1d\xa8\x69\x36\x12\xb6\x28\x99\xfb\x5d\x95\x58\x41\x27\x29\xc9\xb7\x2f\x23\xec\x2b\x8c\x80\xdb\x71\x0b\xa5\x41\x17\x8a\x19\xb8\x90\x55\x28\x4b\xed\x81\x21\x63\x2f\x5c\xd4\xc2\x01\xf8\x06\x08\x3f\xc4\xd6\x9e\xfc\xbd\x6b\x40\x89\xdf\x68\x8b\x49\x34\x78\x0a\xf7\x41\x0e\xd7\x40\xc8\x5b\xfd\x12\xf2\x80\x39\x12\xa6\x2b\x0a\x34\x26\xf1\x72\xa6\x85\x2f\x4c\x83\x49\x1d\xc1\xd6\x35\x35\x32\x52\xf8\x18\xb3\x22\xbf\x97\xde\x03\x70\x13\x50\x8b\x9c\x48\xc0\xad\x0b\xdf\xca\x9d\x6f\x43\xa8\xaa\x3d\xf4\x40\xc0\x2d\x7d\x02\x1e\x32\x76\xcb\x59\x92\xf3\xeb\x2c\xdd\x1d\xfc\x1a\x36\x3c\x5e\xd1\x63\x6f\xd9\x6e\x22\x44\xf8\x4c\x2b\x2d\x78\x4d\x49\x3e\xc3\x77\x4b\x8a\x6d\x49\x1d\x74\x3f\xa4\x4c\xd3\x99\xec\x77\x23\xcc\x53\x67\xee\x05\xa1\x82\x26\xb4\x12\x7b\xfb\xd2\xdd\xa1\x6e\x40\x0a\xf5\x2b\x42\x1d\x9d\xfd\xf7\xfa\xff\x4a\x77\xe4\x23\xd5\x1a\xa1\x4c\xf2\x1f\x08\x7b\x64\xc0\x8a\xb6\x69\x50\xd1\x59\x0c\x1a\x0d\x2d\x98\xa8\x9b\x98\xdf\x0b\x9f\x8c\xac\xd6\xf9\xe2\xc4\x15\x11\x37\xfb\xa2\xdc\xb6\x54\x0b\xc5\x19\xb5\xcc\x20\xb2\x1a\x35\x9e\x8d\x6f\x46\x00\x11\x01\x88\x8a\x71\x62\xeb\xdb\xae\xd9\xa2\xfe\x59\xdd\x01\x47\xc3\x2e\x1c\x64\xf8\x78\x3a\x55\x0a\x54\x84\xda\xcb\x92\x75\x72\x0a\xee\x80\x0b\x05\x24\x5a\x75\x90\x2c\x93\x62\x8a\xc6\xee\xc5\x27\x2b\x51\xa7\x35\x2a\xb2\x87\xb1\x4d\x34\xda\x52\x18\xd8\x4a\x2c\x4f\xed\x14\x7b\xe1\x20\xc0\xfa\x4e\xe3\x5e\xb4\x9d\xb1\x0a\xc4\xc0\xb6\xe6\x7f\x09\x4f\x5d\xfb\x2d\x9a\xae\x14\x81\x21\x83\x1b\xb3\xb3\x64\xc6\x87\x5b\xc3\x8b\x9f\xc4\xd1\xc7\x52\x1c\xf9\xbb\xd4\x93\xc3\x7c\xb3\x50\x90\xd8\x63\xe2\x59\xcf\x90\x4e\x85\x2a\xf1\xd9\x41\x29\xf4\x50\x1b\xf7\x6e\xee\x1c\x8e\xaf\xf4\x37\x2e\xb2\x8c\xcf\x98\x37\x8d\x6d\x2b\x9a\x18\xeb\xfb\xf4\x9c\x8d\x0d\x9e\xa7\xc1\x4d\x7b\xb7\x32\xcb\xd8\x15\xff\xa4\xd3\x2d\x3e\xd3\x1b\x1a\xe9\x79\x8d\xc6\xaf\x4e\xda\xaf\x2c\x2d\xc5\x3e\xb0\xee\xd8\x9b\xa8\xc6\xc6\x7c\x34\x8a\xf9\xff\x00\x63\xc2\x9f\x00\x00\x00\xff\xff\xd4\x5b\x1b\x6a\x49\x08\x00\x00")
+var _templatesBodyContentIndexTmpl = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x02\xff\xa4\x56\x4d\x6f\xdb\x38\x10\xbd\xeb\x57\x30\x5a\x63\x0f\xc1\xda\x8a\xf7\xb4\xd8\x55\x04\x04\xc9\x61\x0b\x14\x69\x80\xe4\x1e\x8c\xa5\x11\x45\x94\xa2\x1c\x72\xe4\x26\x15\xf4\xdf\x0b\x52\x94\x2c\xba\x0a\xea\xa0\x97\x40\x9c\x8f\x37\x6f\x66\x1e\x19\x77\x5d\x81\xa5\x50\xc8\x62\x12\x24\x31\xee\xfb\xa8\xeb\x36\xb7\x8d\x2a\x05\xdf\x3c\x59\x93\xb3\xa0\x2a\xfa\x3e\x8a\x8e\xd1\x79\xa3\x08\x15\xd9\xf8\xb4\x10\x07\x96\x4b\x30\xe6\xda\x99\x41\x28\xd4\x2c\x6f\xe4\xba\x2e\xd6\xdb\xab\xf1\xab\x29\x4b\x83\xb4\xde\xba\xb3\xe4\xeb\x7f\xc6\x0f\xef\xf8\x3b\xce\x22\xc6\xba\x4e\x94\x6c\x73\xa3\x49\xe4\x12\x4d\xdf\x47\x8c\x59\xa3\x06\xc5\x91\xad\x9e\xff\x62\x2b\x18\x7c\xec\xdf\xeb\x9f\xe2\x52\xef\xcb\xdc\x89\xb1\xb4\xda\x66\x29\xb0\x4a\x63\x79\x1d\x77\xdd\x98\xba\x79\x94\x2d\xef\xfb\x38\x9b\x99\x7c\xab\x69\x02\x59\x9a\x54\xdb\x2c\x1a\x21\xf6\x23\x98\xe7\x36\x65\x7c\x32\x77\x1a\x4a\xea\xfb\xc9\x9f\x9a\x3d\xa8\x71\x12\x12\x76\x28\x99\xfb\xbb\xfe\x06\x5a\x09\xc5\xe3\xac\xb0\x19\x69\x62\xe3\xe6\xb0\x7e\xba\x41\x9d\x46\xb3\xd5\xb8\x87\x9b\x96\xaa\x46\x1f\x4b\x0f\xe7\x73\x2a\x17\x58\x42\x2b\x29\xce\x76\x6f\x27\xf4\x47\x8c\xae\x5b\x30\xa1\x34\xe8\x5c\x21\x83\xbe\xf7\x5c\xcf\x6b\x61\x02\xbe\x03\xc2\x0f\xb1\xb5\x95\xff\x6f\x6b\x50\xe2\x3b\xda\x64\x12\x35\x9e\xc2\x7d\x90\xc3\x2d\x10\xf2\x46\xbf\xcd\x79\xc0\x12\x09\xd3\xe6\x39\x1a\x13\x7b\xd9\x24\xb9\x4f\x4c\xba\x6e\x01\x6c\x53\x51\x2d\xe3\x6c\xd1\x67\xc5\x74\x2e\xbd\x27\xe0\x66\x46\x2d\x50\x3c\x01\xb7\x6a\x7f\x2f\x76\xb9\x0d\xa1\xca\x66\xea\x81\x80\x5b\xfa\x04\x7c\xce\xd8\x1d\x17\x49\x2e\x9f\xd3\x64\x3f\xdd\x8b\x79\xc3\xc3\x53\x70\xec\x2d\xdd\x8f\x84\x08\x5f\x69\xad\x05\xaf\x28\xce\x16\xf8\xee\x48\xb1\x1d\xa9\x69\xef\x53\xc8\x38\x9d\x51\x7e\x77\xc2\xbc\xb4\xe6\x51\x10\x2a\xa8\xe7\x52\x62\xef\x5f\xee\x07\xd4\x35\x48\xa1\xbe\x06\xa8\x83\xb2\x7f\x9d\xff\x47\xe1\x4a\x3e\x53\xa5\x11\x8a\x38\xfb\x8c\x70\x40\x06\x2c\x6f\xea\x1a\x15\x5d\x84\xa0\xc1\xd0\x66\x13\x75\x13\xf3\xb6\xf9\xd3\x94\x56\x3a\x8b\x4e\x54\x11\x70\xb3\x2f\xd7\x7d\x43\x95\x50\x9c\x51\xc3\x0c\x22\xab\x50\xe3\xc5\xf0\x36\xcd\x20\x02\x00\x51\x32\x4e\x6c\x73\xdf\xd6\x3b\xd4\x5f\xca\x07\xe0\x68\xd8\x95\x83\x9c\x3f\xd2\x6e\x2b\x39\x2a\x42\xed\xd7\x92\xb6\x72\x74\xee\x81\x0b\x05\x24\x1a\x15\x4f\x5d\x48\x31\x7a\x43\xf5\xe2\x8b\x5d\x51\xab\x35\x2a\xb2\xc5\xd8\x36\x18\x6d\x21\x0c\xec\x24\x16\xa7\x72\x0a\xb5\x30\x2d\x60\xf3\xa0\xf1\x20\x9a\xd6\xd8\x0d\x84\xc0\x36\xe7\x4f\x09\x2f\x6d\xf3\x5f\x30\x5d\x29\x66\x82\x9c\xdd\x98\x3d\xf0\xf1\x1f\x84\x86\x37\x3f\x89\xa3\x8e\xa5\x38\xf2\x77\xa1\x27\xc5\x7c\xb3\x90\x93\x38\x60\xec\x59\x2f\x90\x4e\x84\x2a\xf0\xd5\x41\x29\xf4\x50\x5b\xf7\x6e\xee\x1d\x8e\xcf\xf4\x37\x2e\x90\x8c\x8f\x58\x16\x8d\x6d\x2b\x98\x18\xeb\xba\xe4\x92\x0d\x0d\x5e\x26\xb3\x9b\x76\xf6\x66\x56\xa1\x2a\x7e\x6b\x4f\xf7\xf8\x4a\xef\xec\x48\x2f\xef\x68\xf8\x6a\xa5\xfd\x4a\x93\x42\x1c\xb2\xe8\xa4\x37\x51\x0e\x8d\x79\x6f\xe0\xf3\xbf\x35\x86\x80\x1f\x03\x00\xef\x82\x0b\x4a\xb1\x08\x00\x00")
 
 func templatesBodyContentIndexTmplBytes() ([]byte, error) {
 	return bindataRead(
@@ -259,7 +259,7 @@ func templatesBodyContentIndexTmpl() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "templates/body/content/index.tmpl", size: 2121, mode: os.FileMode(420), modTime: time.Unix(1481713853, 0)}
+	info := bindataFileInfo{name: "templates/body/content/index.tmpl", size: 2225, mode: os.FileMode(420), modTime: time.Unix(1481713853, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -364,7 +364,7 @@ func templatesBodyNavbarTmpl() (*asset, error) {
 	return a, nil
 }
 
-var _templatesHeadHeaderTmpl = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xb4\xcd\x31\xae\x02\x21\x10\x87\xf1\x7e\x4f\x41\xa6\x7f\xc3\x4b\xb6\x75\xbd\x0b\xc2\x7f\x03\x11\x06\xc3\x4c\x63\x08\x77\xb7\x53\x2f\x60\xfb\x2b\xbe\x6f\xce\x84\xb3\x08\x1c\x65\x84\x84\x41\x6b\x6d\x97\x5a\xe4\xee\x06\xea\x41\x6a\xcf\x0a\xcd\x80\x91\xcb\x03\xe7\x41\xde\x0b\x2c\x49\xe0\x5b\xef\xa6\x36\xc2\x23\x26\xe1\xd8\x9b\x7f\x83\xdf\xf9\x9f\x77\x1f\x55\x3f\xc6\xad\x08\x47\x55\xba\xfe\x24\xff\x67\x19\x0d\x5f\x93\x39\x21\x69\xad\xed\x15\x00\x00\xff\xff\xe8\x1b\x48\x83\xe0\x00\x00\x00")
+var _templatesHeadHeaderTmpl = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x02\xff\xb4\x90\xb1\x6a\xc4\x30\x10\x44\xfb\x7c\x85\x50\x9f\x55\xc0\x6d\x9c\xc6\x04\xd2\xfb\x0b\x64\x69\x64\x89\xc8\xab\xa0\x55\x13\x84\xfe\x3d\x90\xc2\x77\x70\x07\x57\x5d\xb5\xf0\x76\x77\x66\x98\xde\x3d\x42\x62\x28\x1d\x61\x3d\xaa\x1e\xe3\xe5\x3d\x27\xfe\x56\x15\x79\xd6\xd2\x7e\x33\x24\x02\x4d\xab\x58\x11\x66\x6d\x0c\xa3\x79\xb6\xb4\x95\xd2\xa4\x55\xfb\xe3\x3c\x93\x2b\x87\x39\x81\x99\xe8\x8d\x26\xe3\x44\x2e\x8c\x8e\xc4\xe4\x44\xf4\xc7\x53\xe4\x5f\x5b\xc4\x81\x2b\x93\xde\x53\x50\x96\xbd\xa2\xa5\x70\x48\x3b\x7d\xa5\x3d\xe6\xb4\xc7\x46\x9f\x6c\xb7\x8c\x7b\x9b\x65\x5d\x97\x6c\x45\x20\x0f\x5b\xe8\xfd\xf6\x7d\x3d\xcf\xc6\xf8\x8f\x00\xf6\x63\x9c\xf3\x6f\x00\x97\xde\x9d\xeb\x6b\x01\x00\x00")
 
 func templatesHeadHeaderTmplBytes() ([]byte, error) {
 	return bindataRead(
@@ -379,7 +379,7 @@ func templatesHeadHeaderTmpl() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "templates/head/header.tmpl", size: 224, mode: os.FileMode(420), modTime: time.Unix(1481713853, 0)}
+	info := bindataFileInfo{name: "templates/head/header.tmpl", size: 363, mode: os.FileMode(420), modTime: time.Unix(1481713853, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }